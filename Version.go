@@ -4,4 +4,4 @@ package lcme
 // Use of this source code is governed by a MIT style
 // license that can be found in the LICENSE file.
 
-const Version = "1.1.7"
+const Version = "1.2.0"
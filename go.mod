@@ -2,4 +2,4 @@ module github.com/GomdimApps/lcme
 
 go 1.21.0
 
-require golang.org/x/sys v0.29.0 // indirect
+require golang.org/x/sys v0.29.0
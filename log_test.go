@@ -0,0 +1,125 @@
+package lcme
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/GomdimApps/lcme/system/logs"
+)
+
+// logOpenPerLine is the original implementation of Log, which opens, appends to and closes
+// the file for every line. It is kept for the benchmarks.
+func logOpenPerLine(filePath string) func(string) {
+	return func(value string) {
+		file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			fmt.Println("Error opening the file:", err)
+			return
+		}
+		defer file.Close()
+
+		if _, err := file.WriteString(value + "\n"); err != nil {
+			fmt.Println("Error writing to the file:", err)
+		}
+	}
+}
+
+func TestLogWritesWithoutClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	t.Cleanup(func() { LogClose() })
+
+	logger := Log(path)
+	logger("first")
+	Log(path)("second")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "first\nsecond\n"; got != want {
+		t.Errorf("file content = %q, want %q", got, want)
+	}
+}
+
+func TestLogReopensAfterClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	t.Cleanup(func() { LogClose() })
+
+	logger := LogBuffered(path)
+	logger("before close")
+	if err := LogClose(); err != nil {
+		t.Fatal(err)
+	}
+	logger("after close")
+	if err := LogClose(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "before close\nafter close\n"; got != want {
+		t.Errorf("file content = %q, want %q", got, want)
+	}
+}
+
+func TestNewLoggerSharesLogFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	t.Cleanup(func() { LogClose() })
+
+	logger, err := NewLogger(path, logs.LevelInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("from logger")
+	Log(path)("from log")
+	if err := LogClose(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("after close")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[0], "INFO from logger") || lines[1] != "from log" ||
+		!strings.HasSuffix(lines[2], "INFO after close") {
+		t.Errorf("file content = %q", data)
+	}
+}
+
+func benchmarkLog(b *testing.B, open func(string) func(string)) {
+	path := filepath.Join(b.TempDir(), "bench.log")
+	logger := open(path)
+	line := strings.Repeat("x", 100)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger(line)
+	}
+	b.StopTimer()
+	if err := LogClose(); err != nil {
+		b.Fatal(err)
+	}
+}
+
+func BenchmarkLogOpenPerLine(b *testing.B) {
+	benchmarkLog(b, logOpenPerLine)
+}
+
+func BenchmarkLog(b *testing.B) {
+	benchmarkLog(b, Log)
+}
+
+func BenchmarkLogBuffered(b *testing.B) {
+	benchmarkLog(b, LogBuffered)
+}
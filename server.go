@@ -5,6 +5,8 @@ import (
 	"archive/zip"
 	"bytes"
 	"compress/gzip"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -13,6 +15,7 @@ import (
 
 	"github.com/GomdimApps/lcme/system"
 	"github.com/GomdimApps/lcme/system/compressfiles"
+	"github.com/GomdimApps/lcme/system/logs"
 	"github.com/GomdimApps/lcme/system/threads"
 	"github.com/GomdimApps/lcme/system/utils"
 )
@@ -21,15 +24,16 @@ import (
 // system distribution, RAM usage, disk space, CPU information,
 // network and hardware information.
 func GetInfoServer() system.ServerInfo {
-	distroInfo, _ := system.GetDistroInfo()
-	return system.ServerInfo{
-		Distribution: distroInfo,
-		RAM:          system.GetRAMInfo(),
-		Disk:         system.GetDiskInfo("/"),
-		CPU:          system.GetCPUInfo(),
-		Network:      system.GetNetworkInfo(),
-		Hardware:     system.GetHardwareInfo(),
-	}
+	info, _ := GetInfoServerWith(context.Background(), system.InfoOptions{})
+	return info
+}
+
+// GetInfoServerWith collects only the sections of the server information selected in opts,
+// running the collectors concurrently. The CPU usage and network rates are sampled over the
+// windows set in opts. If ctx is done first, the sections collected so far are returned
+// along with the context error.
+func GetInfoServerWith(ctx context.Context, opts system.InfoOptions) (system.ServerInfo, error) {
+	return system.GetServerInfo(ctx, opts)
 }
 
 // Shell executes a command in the terminal and returns the result as a string,
@@ -40,26 +44,142 @@ func Shell(command string) (string, error) {
 
 // Log returns a log function that writes messages to a specified .log file.
 // If the file does not have the .log extension, it displays an error.
+// Every call to Log for the same path shares one open file, and each message is written to it
+// before the function returns. LogBuffered trades that for speed.
 func Log(filePath string) func(string) {
-	if !strings.HasSuffix(filePath, ".log") {
+	return LogWith(filePath, logs.FileOptions{})
+}
+
+// LogRotating returns a log function like Log that also rotates the .log file according to rotation.
+// The file can be rotated when it reaches a maximum size, every hour or every day. Rotated files are
+// renamed with a timestamp and gzip-compressed in the background, keeping at most rotation.MaxBackups of them.
+// A path that Log is already writing to cannot be opened with a rotation; see LogWith.
+func LogRotating(filePath string, rotation logs.Rotation) func(string) {
+	return LogWith(filePath, logs.FileOptions{Rotation: rotation})
+}
+
+// LogBuffered returns a log function like Log that keeps messages in a buffer flushed every second,
+// which is much faster for thousands of lines a second. Call LogClose before the program exits
+// so that the buffered messages are not lost.
+func LogBuffered(filePath string) func(string) {
+	return LogWith(filePath, logs.FileOptions{Buffered: true})
+}
+
+// LogWith returns a log function like Log that writes through a file opened with opts, for control
+// over buffering, fsync, the write queue and rotation. Functions returned for the same path share
+// the open file, so they must use the same options: when the path is already open with other
+// options, e.g. by Log before LogRotating, an error is displayed and the function does not write
+// until LogClose.
+func LogWith(filePath string, opts logs.FileOptions) func(string) {
+	if _, err := logs.OpenSharedFile(filePath, opts); errors.Is(err, logs.ErrExtension) {
 		fmt.Println("Error: The file must have a .log extension")
 		return func(value string) {
 			fmt.Println("Error: The file must have a .log extension")
 		}
+	} else if err != nil {
+		fmt.Println("Error opening the file:", err)
 	}
 
 	return func(value string) {
-		file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if redactor := logs.GetRedactor(); redactor != nil {
+			value = redactor.RedactString(value)
+		}
+		line := []byte(value + "\n")
+		err := sharedLogFile{filePath, opts}.do(func(file *logs.File) error {
+			_, err := file.Write(line)
+			return err
+		})
 		if err != nil {
-			fmt.Println("Error opening the file:", err)
-			return
+			fmt.Println("Error writing to the file:", err)
 		}
-		defer file.Close()
+	}
+}
 
-		if _, err := file.WriteString(value + "\n"); err != nil {
-			fmt.Println("Error writing to the file:", err)
+// sharedLogFile is a Sink that writes to the shared File of a path, opening it again
+// when it has been closed, e.g. by LogClose.
+type sharedLogFile struct {
+	path string
+	opts logs.FileOptions
+}
+
+// do calls fn with the shared File, retrying once with a new File if it was closed in the meantime.
+func (s sharedLogFile) do(fn func(*logs.File) error) error {
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := logs.OpenSharedFile(s.path, s.opts)
+		if err != nil {
+			return err
+		}
+		if err := fn(file); !errors.Is(err, logs.ErrClosed) {
+			return err
 		}
 	}
+	return logs.ErrClosed
+}
+
+// WriteEntry writes the entry to the shared File.
+func (s sharedLogFile) WriteEntry(e logs.Entry) error {
+	return s.do(func(file *logs.File) error { return file.WriteEntry(e) })
+}
+
+// Close closes the shared File; the other writers of the path open it again.
+func (s sharedLogFile) Close() error {
+	return s.do(func(file *logs.File) error { return file.Close() })
+}
+
+// LogClose flushes the buffered lines of every log file opened by Log, LogRotating, LogBuffered,
+// LogWith or NewLogger and closes them. It should be called before the program exits.
+// Log functions keep working afterwards and open their file again.
+func LogClose() error {
+	return logs.CloseAll()
+}
+
+// NewMultiLogger returns a logger that sends every entry to several sinks at once, e.g. errors
+// to stderr as text, everything to a JSON .log file and warnings or worse to syslog.
+// Each route has its own minimum level, filter and format, and a slow or failing sink does not block the others.
+// Call Close on the logger to flush and close the sinks.
+func NewMultiLogger(routes ...logs.Route) *logs.Logger {
+	level := logs.LevelError
+	for _, route := range routes {
+		level = min(level, route.Level)
+	}
+	return logs.New(logs.NewMultiSink(routes...), level)
+}
+
+// LogTail returns the last n lines of the specified log file, oldest first.
+func LogTail(filePath string, n int) ([]string, error) {
+	return logs.Tail(filePath, n)
+}
+
+// LogFollow returns a channel with the last n lines of the specified log file followed by every line
+// appended to it, like tail -F. It keeps following the file when it is rotated or truncated.
+// The channels are closed when ctx is done; read errors are sent on the error channel.
+func LogFollow(ctx context.Context, filePath string, n int) (<-chan string, <-chan error) {
+	return logs.Follow(ctx, filePath, logs.FollowOptions{Lines: n})
+}
+
+// LogQuery returns the lines of the specified log file that match the query, oldest first.
+// It understands JSON lines and plain text lines, and with query.Archives set it also
+// reads the rotated files, including the gzip-compressed ones.
+func LogQuery(filePath string, query logs.Query) ([]logs.Record, error) {
+	return logs.QueryFile(filePath, query)
+}
+
+// SetLogRedactor sets the redactor applied to every message and field written by Log, LogRotating,
+// NewLogger and NewMultiLogger, whatever the sink. Secrets are replaced with [REDACTED] and counted
+// by the redactor. A redactor with the default rules is installed at startup; a nil redactor disables redaction.
+func SetLogRedactor(redactor *logs.Redactor) {
+	logs.SetRedactor(redactor)
+}
+
+// NewLogger returns a leveled, structured logger that writes to the specified .log file.
+// Entries below level are discarded; the level can be changed later with SetLevel.
+// The logger's Handler method provides a slog.Handler that writes to the same file.
+// The file is shared with Log and the other loggers of the same path, so their lines do not interleave.
+func NewLogger(filePath string, level logs.Level) (*logs.Logger, error) {
+	if _, err := logs.OpenSharedFile(filePath, logs.FileOptions{}); err != nil {
+		return nil, err
+	}
+	return logs.New(sharedLogFile{path: filePath}, level), nil
 }
 
 // GetFolderSize returns the size of the specified folder in bytes.
@@ -114,6 +234,7 @@ func GetFileInfo(dir string, files ...string) ([]system.FileInfo, error) {
 }
 
 // MonitorNetworkRates continuously calculates and returns the download and upload rates.
+// It never stops and prints errors; MonitorNetworkRatesWith can be cancelled and reports the rates of each interface.
 func MonitorNetworkRates() chan system.NetworkInfo {
 	ratesChan := make(chan system.NetworkInfo)
 	go func() {
@@ -145,6 +266,19 @@ func MonitorNetworkRates() chan system.NetworkInfo {
 	return ratesChan
 }
 
+// MonitorNetworkRatesWith continuously calculates the traffic rates, in bytes and packets per second,
+// of the selected network interfaces. The channels are closed when ctx is done; errors are sent on the error channel.
+func MonitorNetworkRatesWith(ctx context.Context, opts system.NetworkMonitorOptions) (<-chan system.NetworkRates, <-chan error) {
+	return system.MonitorNetworkRatesWith(ctx, opts)
+}
+
+// MonitorDiskIO continuously samples the disk activity from /proc/diskstats: IOPS, throughput, latency,
+// queue depth and utilization of each device and partition. The channels are closed when ctx is done;
+// read errors are sent on the error channel.
+func MonitorDiskIO(ctx context.Context, opts system.DiskMonitorOptions) (<-chan system.DiskIOSample, <-chan error) {
+	return system.MonitorDiskIO(ctx, opts)
+}
+
 // ScaleFork accepts a task function and manages its execution using the Engine.
 func ScaleFork(task threads.Task) {
 	engine := threads.NewEngine(runtime.NumCPU())
@@ -0,0 +1,216 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// blockSysfsPath is the sysfs directory listing the block devices.
+const blockSysfsPath = "/sys/block"
+
+// blockSectorSize is the unit of the size attributes in /sys/block, whatever the device's sector size.
+const blockSectorSize = 512
+
+// BlockPartition is a partition of a block device. Sizes are in bytes.
+type BlockPartition struct {
+	Name       string // Kernel name, e.g. "sda1" or "nvme0n1p1".
+	Path       string // Device node, e.g. "/dev/sda1".
+	MajorMinor string
+	Number     int
+	Start      uint64 // Offset from the start of the device.
+	Size       uint64
+	ReadOnly   bool
+	Holders    []string // Devices built on top of the partition, e.g. "dm-0" for LVM or dm-crypt.
+}
+
+// BlockDevice is a block device from /sys/block. Sizes are in bytes. Model, Vendor and
+// Serial are empty when the driver does not report them, as with most virtual disks.
+type BlockDevice struct {
+	Name               string // Kernel name, e.g. "sda", "nvme0n1" or "dm-0".
+	Path               string // Device node, e.g. "/dev/sda".
+	MajorMinor         string
+	Type               string // "disk", "loop", "dm", "md", "zram" or "rbd".
+	DMName             string // Device mapper name, e.g. "vg0-root" or "luks-...", for dm devices.
+	Size               uint64
+	Model              string
+	Vendor             string
+	Serial             string
+	WWID               string
+	Rotational         bool // True for spinning disks, false for SSDs and NVMe.
+	Removable          bool
+	ReadOnly           bool
+	LogicalSectorSize  uint64
+	PhysicalSectorSize uint64
+	Scheduler          string   // Active IO scheduler, e.g. "mq-deadline" or "none".
+	Schedulers         []string // Available IO schedulers.
+	Partitions         []BlockPartition
+	Holders            []string // Devices built on top of this one, e.g. "dm-0".
+	Slaves             []string // Devices this one is built on, e.g. "sda2" for an LVM volume.
+}
+
+// GetBlockDevices lists the block devices of /sys/block with their partitions,
+// hardware details, queue settings and the holder/slave links of LVM, RAID and dm-crypt stacks.
+func GetBlockDevices() ([]BlockDevice, error) {
+	return readBlockDevices(blockSysfsPath)
+}
+
+// readBlockDevices reads the block devices of root, a directory laid out like /sys/block.
+func readBlockDevices(root string) ([]BlockDevice, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var devices []BlockDevice
+	for _, entry := range entries {
+		devices = append(devices, readBlockDevice(filepath.Join(root, entry.Name())))
+	}
+	sort.Slice(devices, func(i, j int) bool { return naturalLess(devices[i].Name, devices[j].Name) })
+	return devices, nil
+}
+
+// readBlockDevice reads the attributes of a device from its directory in /sys/block.
+func readBlockDevice(dir string) BlockDevice {
+	name := filepath.Base(dir)
+	device := BlockDevice{
+		Name:       name,
+		Path:       "/dev/" + name,
+		Type:       blockDeviceType(dir, name),
+		Holders:    readDirNames(filepath.Join(dir, "holders")),
+		Slaves:     readDirNames(filepath.Join(dir, "slaves")),
+		Rotational: readSysfsBool(filepath.Join(dir, "queue", "rotational")),
+		Removable:  readSysfsBool(filepath.Join(dir, "removable")),
+		ReadOnly:   readSysfsBool(filepath.Join(dir, "ro")),
+	}
+	device.MajorMinor, _ = readSysfsString(filepath.Join(dir, "dev"))
+	device.DMName, _ = readSysfsString(filepath.Join(dir, "dm", "name"))
+	if sectors, err := readSysfsUint(filepath.Join(dir, "size")); err == nil {
+		device.Size = sectors * blockSectorSize
+	}
+	device.LogicalSectorSize, _ = readSysfsUint(filepath.Join(dir, "queue", "logical_block_size"))
+	device.PhysicalSectorSize, _ = readSysfsUint(filepath.Join(dir, "queue", "physical_block_size"))
+	device.Model, _ = readSysfsString(filepath.Join(dir, "device", "model"))
+	device.Vendor, _ = readSysfsString(filepath.Join(dir, "device", "vendor"))
+	device.Serial = readBlockSerial(dir)
+	if wwid, err := readSysfsString(filepath.Join(dir, "wwid")); err == nil {
+		device.WWID = wwid
+	} else {
+		device.WWID, _ = readSysfsString(filepath.Join(dir, "device", "wwid"))
+	}
+	if schedulers, err := readSysfsString(filepath.Join(dir, "queue", "scheduler")); err == nil {
+		device.Scheduler, device.Schedulers = parseScheduler(schedulers)
+	}
+
+	// Partitions are the subdirectories of the device that have a partition attribute.
+	for _, child := range readDirNames(dir) {
+		if fileExists(filepath.Join(dir, child, "partition")) {
+			device.Partitions = append(device.Partitions, readBlockPartition(filepath.Join(dir, child)))
+		}
+	}
+	sort.Slice(device.Partitions, func(i, j int) bool { return device.Partitions[i].Number < device.Partitions[j].Number })
+	return device
+}
+
+// readBlockPartition reads the attributes of a partition from its directory under the device.
+func readBlockPartition(dir string) BlockPartition {
+	name := filepath.Base(dir)
+	partition := BlockPartition{
+		Name:     name,
+		Path:     "/dev/" + name,
+		ReadOnly: readSysfsBool(filepath.Join(dir, "ro")),
+		Holders:  readDirNames(filepath.Join(dir, "holders")),
+	}
+	partition.MajorMinor, _ = readSysfsString(filepath.Join(dir, "dev"))
+	partition.Number, _ = readSysfsInt(filepath.Join(dir, "partition"))
+	if start, err := readSysfsUint(filepath.Join(dir, "start")); err == nil {
+		partition.Start = start * blockSectorSize
+	}
+	if sectors, err := readSysfsUint(filepath.Join(dir, "size")); err == nil {
+		partition.Size = sectors * blockSectorSize
+	}
+	return partition
+}
+
+// blockDeviceType classifies a block device from its name and sysfs directory.
+func blockDeviceType(dir, name string) string {
+	switch {
+	case fileExists(filepath.Join(dir, "dm")):
+		return "dm"
+	case fileExists(filepath.Join(dir, "md")):
+		return "md"
+	case strings.HasPrefix(name, "loop"):
+		return "loop"
+	case strings.HasPrefix(name, "zram"):
+		return "zram"
+	case strings.HasPrefix(name, "rbd"):
+		return "rbd"
+	}
+	return "disk"
+}
+
+// readBlockSerial reads the serial number from the attribute used by the driver: virtio and
+// NVMe provide it directly, SCSI and SATA disks through the unit serial number VPD page.
+func readBlockSerial(dir string) string {
+	for _, path := range []string{filepath.Join(dir, "serial"), filepath.Join(dir, "device", "serial")} {
+		if serial, err := readSysfsString(path); err == nil && serial != "" {
+			return serial
+		}
+	}
+	// VPD page 0x80: a 4-byte header followed by the serial number.
+	if page, err := os.ReadFile(filepath.Join(dir, "device", "vpd_pg80")); err == nil && len(page) > 4 {
+		return strings.TrimSpace(string(page[4:]))
+	}
+	return ""
+}
+
+// parseScheduler parses a scheduler attribute such as "none [mq-deadline] kyber bfq",
+// where the active scheduler is in brackets.
+func parseScheduler(value string) (string, []string) {
+	var active string
+	var available []string
+	for _, field := range strings.Fields(value) {
+		if strings.HasPrefix(field, "[") && strings.HasSuffix(field, "]") {
+			field = strings.Trim(field, "[]")
+			active = field
+		}
+		available = append(available, field)
+	}
+	return active, available
+}
+
+// readDirNames returns the entry names of a directory, or nil if it cannot be read.
+func readDirNames(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+// readSysfsUint reads a sysfs attribute holding an unsigned integer.
+func readSysfsUint(path string) (uint64, error) {
+	value, err := readSysfsString(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// readSysfsBool reads a sysfs attribute holding 0 or 1; missing attributes are false.
+func readSysfsBool(path string) bool {
+	value, err := readSysfsInt(path)
+	return err == nil && value != 0
+}
+
+// fileExists reports whether path exists.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
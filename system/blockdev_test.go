@@ -0,0 +1,145 @@
+package system
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadBlockDevices(t *testing.T) {
+	root := t.TempDir()
+	writeSysfsFixture(t, root, map[string]string{
+		// A SATA disk with two partitions, the second one holding an LVM volume.
+		"sda/dev":                       "8:0",
+		"sda/size":                      "1953525168",
+		"sda/ro":                        "0",
+		"sda/removable":                 "0",
+		"sda/queue/rotational":          "1",
+		"sda/queue/logical_block_size":  "512",
+		"sda/queue/physical_block_size": "4096",
+		"sda/queue/scheduler":           "none [mq-deadline] kyber bfq",
+		"sda/device/model":              "ST1000DM010-2EP1",
+		"sda/device/vendor":             "ATA",
+		"sda/device/wwid":               "t10.ATA     ST1000DM010-2EP102                      Z9A1B2C3",
+		"sda/device/vpd_pg80":           "\x00\x80\x00\x14        Z9A1B2C3",
+		"sda/sda1/dev":                  "8:1",
+		"sda/sda1/partition":            "1",
+		"sda/sda1/start":                "2048",
+		"sda/sda1/size":                 "1048576",
+		"sda/sda1/ro":                   "0",
+		"sda/sda2/dev":                  "8:2",
+		"sda/sda2/partition":            "2",
+		"sda/sda2/start":                "1050624",
+		"sda/sda2/size":                 "1952474511",
+		"sda/sda2/ro":                   "0",
+		"sda/sda2/holders/dm-0":         "",
+		// An NVMe disk reports its serial number directly.
+		"nvme0n1/dev":                    "259:0",
+		"nvme0n1/size":                   "500118192",
+		"nvme0n1/queue/rotational":       "0",
+		"nvme0n1/queue/scheduler":        "[none] mq-deadline",
+		"nvme0n1/wwid":                   "eui.0025388b91b2c3d4",
+		"nvme0n1/device/model":           "Samsung SSD 970 EVO Plus 500GB",
+		"nvme0n1/device/serial":          "S4EVNX0N123456A",
+		"dm-0/dev":                       "253:0",
+		"dm-0/size":                      "1952473088",
+		"dm-0/dm/name":                   "vg0-root",
+		"dm-0/slaves/sda2":               "",
+		"loop10/dev":                     "7:10",
+		"loop10/size":                    "0",
+		"loop10/ro":                      "1",
+		"loop2/dev":                      "7:2",
+		"loop2/size":                     "131072",
+		"zram0/dev":                      "252:0",
+		"zram0/size":                     "16777216",
+		"zram0/queue/logical_block_size": "4096",
+	})
+
+	devices, err := readBlockDevices(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, d := range devices {
+		names = append(names, d.Name)
+	}
+	if want := []string{"dm-0", "loop2", "loop10", "nvme0n1", "sda", "zram0"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("devices = %v, want %v", names, want)
+	}
+
+	sda := BlockDevice{
+		Name:               "sda",
+		Path:               "/dev/sda",
+		MajorMinor:         "8:0",
+		Type:               "disk",
+		Size:               1953525168 * 512,
+		Model:              "ST1000DM010-2EP1",
+		Vendor:             "ATA",
+		Serial:             "Z9A1B2C3",
+		WWID:               "t10.ATA     ST1000DM010-2EP102                      Z9A1B2C3",
+		Rotational:         true,
+		LogicalSectorSize:  512,
+		PhysicalSectorSize: 4096,
+		Scheduler:          "mq-deadline",
+		Schedulers:         []string{"none", "mq-deadline", "kyber", "bfq"},
+		Partitions: []BlockPartition{
+			{Name: "sda1", Path: "/dev/sda1", MajorMinor: "8:1", Number: 1, Start: 2048 * 512, Size: 1048576 * 512},
+			{Name: "sda2", Path: "/dev/sda2", MajorMinor: "8:2", Number: 2, Start: 1050624 * 512, Size: 1952474511 * 512,
+				Holders: []string{"dm-0"}},
+		},
+	}
+	nvme := BlockDevice{
+		Name:       "nvme0n1",
+		Path:       "/dev/nvme0n1",
+		MajorMinor: "259:0",
+		Type:       "disk",
+		Size:       500118192 * 512,
+		Model:      "Samsung SSD 970 EVO Plus 500GB",
+		Serial:     "S4EVNX0N123456A",
+		WWID:       "eui.0025388b91b2c3d4",
+		Scheduler:  "none",
+		Schedulers: []string{"none", "mq-deadline"},
+	}
+	dm := BlockDevice{
+		Name:       "dm-0",
+		Path:       "/dev/dm-0",
+		MajorMinor: "253:0",
+		Type:       "dm",
+		DMName:     "vg0-root",
+		Size:       1952473088 * 512,
+		Slaves:     []string{"sda2"},
+	}
+	loop10 := BlockDevice{Name: "loop10", Path: "/dev/loop10", MajorMinor: "7:10", Type: "loop", ReadOnly: true}
+	zram := BlockDevice{Name: "zram0", Path: "/dev/zram0", MajorMinor: "252:0", Type: "zram", Size: 16777216 * 512, LogicalSectorSize: 4096}
+	for i, want := range map[int]BlockDevice{0: dm, 2: loop10, 3: nvme, 4: sda, 5: zram} {
+		if !reflect.DeepEqual(devices[i], want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", want.Name, devices[i], want)
+		}
+	}
+}
+
+func TestReadBlockDevicesMissing(t *testing.T) {
+	if _, err := readBlockDevices(filepath.Join(t.TempDir(), "block")); err == nil {
+		t.Error("readBlockDevices succeeded without a sysfs directory")
+	}
+}
+
+func TestParseScheduler(t *testing.T) {
+	tests := []struct {
+		value      string
+		active     string
+		schedulers []string
+	}{
+		{"none [mq-deadline] kyber bfq", "mq-deadline", []string{"none", "mq-deadline", "kyber", "bfq"}},
+		{"[none]", "none", []string{"none"}},
+		// Without brackets, no scheduler is marked as active.
+		{"none", "", []string{"none"}},
+		{"", "", nil},
+	}
+	for _, tt := range tests {
+		active, schedulers := parseScheduler(tt.value)
+		if active != tt.active || !reflect.DeepEqual(schedulers, tt.schedulers) {
+			t.Errorf("parseScheduler(%q) = %q, %q; want %q, %q", tt.value, active, schedulers, tt.active, tt.schedulers)
+		}
+	}
+}
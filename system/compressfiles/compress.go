@@ -3,6 +3,7 @@ package compressfiles
 import (
 	"archive/tar"
 	"archive/zip"
+	"compress/gzip"
 	"io"
 	"os"
 	"path/filepath"
@@ -85,3 +86,40 @@ func AddFileToTar(tarWriter *tar.Writer, filename, baseFolder string) error {
 	_, err = io.Copy(tarWriter, fileToTar)
 	return err
 }
+
+// GzipFile comprime o arquivo src no formato GZIP, gravando o resultado em dst.
+// Em caso de erro, o arquivo dst parcialmente escrito é removido e src é mantido.
+func GzipFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	gzipWriter := gzip.NewWriter(out)
+	gzipWriter.Name = filepath.Base(src)
+	gzipWriter.ModTime = info.ModTime()
+
+	_, err = io.Copy(gzipWriter, in)
+	if closeErr := gzipWriter.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return nil
+}
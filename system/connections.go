@@ -0,0 +1,235 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/GomdimApps/lcme/system/utils"
+)
+
+// Endpoint is the address and port of one end of a connection.
+type Endpoint struct {
+	IP   net.IP
+	Port uint16
+}
+
+// String returns the endpoint as "ip:port", or "[ip]:port" for IPv6.
+func (e Endpoint) String() string {
+	return net.JoinHostPort(e.IP.String(), strconv.Itoa(int(e.Port)))
+}
+
+// Connection is a TCP or UDP socket from /proc/net/tcp, tcp6, udp and udp6.
+// PID and Command are zero when the owning process is unknown, either because process
+// resolution was skipped or because the process belongs to another user and the caller is not root.
+type Connection struct {
+	Protocol string // "tcp" or "udp".
+	Family   string // "ipv4" or "ipv6".
+	Local    Endpoint
+	Remote   Endpoint
+	State    string // TCP state such as "LISTEN", "ESTABLISHED" or "TIME_WAIT"; "UNCONN" or "ESTABLISHED" for UDP.
+	TxQueue  uint64 // Bytes waiting to be sent.
+	RxQueue  uint64 // Bytes waiting to be read, or the accept backlog of a listening socket.
+	UID      int
+	Inode    uint64
+	PID      int
+	Command  string
+}
+
+// tcpStates are the TCP states in the st column of /proc/net/tcp, from include/net/tcp_states.h.
+var tcpStates = map[uint64]string{
+	0x01: "ESTABLISHED",
+	0x02: "SYN_SENT",
+	0x03: "SYN_RECV",
+	0x04: "FIN_WAIT1",
+	0x05: "FIN_WAIT2",
+	0x06: "TIME_WAIT",
+	0x07: "CLOSE",
+	0x08: "CLOSE_WAIT",
+	0x09: "LAST_ACK",
+	0x0A: "LISTEN",
+	0x0B: "CLOSING",
+	0x0C: "NEW_SYN_RECV",
+}
+
+// socketTable is one of the /proc/net files listing TCP or UDP sockets.
+type socketTable struct {
+	path     string
+	protocol string
+	family   string
+}
+
+var socketTables = []socketTable{
+	{"/proc/net/tcp", "tcp", "ipv4"},
+	{"/proc/net/tcp6", "tcp", "ipv6"},
+	{"/proc/net/udp", "udp", "ipv4"},
+	{"/proc/net/udp6", "udp", "ipv6"},
+}
+
+// ConnectionFilter selects the connections returned by GetConnectionsWith.
+type ConnectionFilter struct {
+	Protocols   []string // "tcp" and/or "udp". Empty selects both.
+	Families    []string // "ipv4" and/or "ipv6". Empty selects both.
+	States      []string // States to keep, e.g. "LISTEN". Empty keeps every state.
+	SkipProcess bool     // Do not resolve the owning processes, which scans /proc/[pid]/fd.
+}
+
+// GetConnections returns every TCP and UDP socket with its owning process, like ss -tuanp.
+func GetConnections() ([]Connection, error) {
+	return GetConnectionsWith(ConnectionFilter{})
+}
+
+// GetConnectionsWith returns the sockets selected by filter.
+func GetConnectionsWith(filter ConnectionFilter) ([]Connection, error) {
+	var connections []Connection
+	for _, table := range socketTables {
+		if len(filter.Protocols) > 0 && !containsString(filter.Protocols, table.protocol) {
+			continue
+		}
+		if len(filter.Families) > 0 && !containsString(filter.Families, table.family) {
+			continue
+		}
+		tableConnections, err := readSocketTable(table)
+		if os.IsNotExist(err) {
+			continue // IPv6 is disabled.
+		} else if err != nil {
+			return nil, err
+		}
+		for _, connection := range tableConnections {
+			if len(filter.States) == 0 || containsString(filter.States, connection.State) {
+				connections = append(connections, connection)
+			}
+		}
+	}
+
+	if !filter.SkipProcess && len(connections) > 0 {
+		owners := socketOwners()
+		for i := range connections {
+			if owner, ok := owners[connections[i].Inode]; ok {
+				connections[i].PID = owner.PID
+				connections[i].Command = owner.Command
+			}
+		}
+	}
+	return connections, nil
+}
+
+// readSocketTable parses a /proc/net/tcp-like file. Lines look like
+// "0: 0100007F:0CEA 00000000:0000 0A 00000000:00000000 00:00000000 00000000 1000 0 12345 ...".
+func readSocketTable(table socketTable) ([]Connection, error) {
+	file, err := os.Open(table.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var connections []Connection
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // Skip the header line
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		local, err := parseEndpoint(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %v", table.path, err)
+		}
+		remote, err := parseEndpoint(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %v", table.path, err)
+		}
+		connection := Connection{
+			Protocol: table.protocol,
+			Family:   table.family,
+			Local:    local,
+			Remote:   remote,
+		}
+		state, _ := strconv.ParseUint(fields[3], 16, 8)
+		connection.State = socketState(table.protocol, state)
+		if tx, rx, ok := strings.Cut(fields[4], ":"); ok {
+			connection.TxQueue, _ = strconv.ParseUint(tx, 16, 64)
+			connection.RxQueue, _ = strconv.ParseUint(rx, 16, 64)
+		}
+		connection.UID, _ = strconv.Atoi(fields[7])
+		connection.Inode, _ = strconv.ParseUint(fields[9], 10, 64)
+		connections = append(connections, connection)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", table.path, err)
+	}
+	return connections, nil
+}
+
+// socketState names the state column. UDP sockets reuse the TCP values: a connected socket
+// is ESTABLISHED and an unconnected one CLOSE, which ss shows as UNCONN.
+func socketState(protocol string, state uint64) string {
+	if protocol == "udp" && state == 0x07 {
+		return "UNCONN"
+	}
+	if name, ok := tcpStates[state]; ok {
+		return name
+	}
+	return fmt.Sprintf("UNKNOWN(%02X)", state)
+}
+
+// parseEndpoint parses an address in the form "0100007F:0CEA".
+func parseEndpoint(addr string) (Endpoint, error) {
+	hexIP, hexPort, ok := strings.Cut(addr, ":")
+	ip := utils.ParseHexIP(hexIP)
+	if !ok || ip == nil {
+		return Endpoint{}, fmt.Errorf("invalid address %q", addr)
+	}
+	return Endpoint{IP: ip, Port: parsePort(hexPort)}, nil
+}
+
+// socketOwner is the process holding a socket.
+type socketOwner struct {
+	PID     int
+	Command string
+}
+
+// socketOwners maps socket inodes to the process holding them by reading the /proc/[pid]/fd links,
+// which look like "socket:[12345]". A socket shared by several processes, e.g. after fork, is
+// attributed to the first one found. Processes of other users are skipped unless the caller is root.
+func socketOwners() map[uint64]socketOwner {
+	owners := make(map[uint64]socketOwner)
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return owners
+	}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join("/proc", entry.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		var command string
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(link, "socket:[") {
+				continue
+			}
+			inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]"), 10, 64)
+			if err != nil {
+				continue
+			}
+			if _, ok := owners[inode]; ok {
+				continue
+			}
+			if command == "" {
+				command, _ = readSysfsString(filepath.Join("/proc", entry.Name(), "comm"))
+			}
+			owners[inode] = socketOwner{PID: pid, Command: command}
+		}
+	}
+	return owners
+}
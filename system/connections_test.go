@@ -0,0 +1,113 @@
+package system
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+// socketTableHeader is the first line of /proc/net/tcp, skipped by readSocketTable.
+const socketTableHeader = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+
+func TestReadSocketTableTCPStates(t *testing.T) {
+	want := []string{"ESTABLISHED", "SYN_SENT", "SYN_RECV", "FIN_WAIT1", "FIN_WAIT2", "TIME_WAIT",
+		"CLOSE", "CLOSE_WAIT", "LAST_ACK", "LISTEN", "CLOSING", "NEW_SYN_RECV", "UNKNOWN(0D)"}
+	var b strings.Builder
+	b.WriteString(socketTableHeader)
+	for i := range want {
+		fmt.Fprintf(&b, "%4d: 1401A8C0:%04X 0500000A:01BB %02X 00000000:00000000 00:00000000 00000000  1000        0 %d 1 0000000000000000 20 4 30 10 -1\n",
+			i, 40000+i, i+1, 5000+i)
+	}
+	path := writeProcFixture(t, "tcp", b.String())
+
+	connections, err := readSocketTable(socketTable{path, "tcp", "ipv4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(connections) != len(want) {
+		t.Fatalf("got %d connections, want %d", len(connections), len(want))
+	}
+	for i, c := range connections {
+		if c.State != want[i] {
+			t.Errorf("state %02X = %s, want %s", i+1, c.State, want[i])
+		}
+		if local := fmt.Sprintf("192.168.1.20:%d", 40000+i); c.Local.String() != local {
+			t.Errorf("local = %s, want %s", c.Local, local)
+		}
+		if c.Remote.String() != "10.0.0.5:443" {
+			t.Errorf("remote = %s, want 10.0.0.5:443", c.Remote)
+		}
+		if c.Inode != uint64(5000+i) || c.UID != 1000 || c.Protocol != "tcp" || c.Family != "ipv4" {
+			t.Errorf("connection %d = %+v", i, c)
+		}
+	}
+}
+
+func TestReadSocketTableIPv6(t *testing.T) {
+	path := writeProcFixture(t, "tcp6", socketTableHeader+
+		"   0: 00000000000000000000000000000000:0016 00000000000000000000000000000000:0000 0A 00000000:00000080 00:00000000 00000000     0        0 2001 1 0000000000000000 100 0 0 10 0\n"+
+		"   1: 00000000000000000000000001000000:1F90 00000000000000000000000001000000:D431 01 00000000:00000000 00:00000000 00000000  1000        0 2002 1 0000000000000000 20 4 30 10 -1\n"+
+		"   2: 0000000000000000FFFF00000100007F:0CEA 0000000000000000FFFF00000100007F:A001 01 00000000:00000000 00:00000000 00000000  1000        0 2003 1 0000000000000000 20 4 30 10 -1\n"+
+		"   3: B80D0120000000002E8A000034737003:01BB 000080FE0000000023FEFF010A896745:C350 08 0000002A:00000000 00:00000000 00000000    33        0 2004 1 0000000000000000 20 4 30 10 -1\n")
+
+	connections, err := readSocketTable(socketTable{path, "tcp", "ipv6"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		local, remote, state string
+		rxQueue, txQueue     uint64
+	}{
+		{"[::]:22", "[::]:0", "LISTEN", 128, 0},
+		{"[::1]:8080", "[::1]:54321", "ESTABLISHED", 0, 0},
+		{"127.0.0.1:3306", "127.0.0.1:40961", "ESTABLISHED", 0, 0},
+		{"[2001:db8::8a2e:370:7334]:443", "[fe80::1ff:fe23:4567:890a]:50000", "CLOSE_WAIT", 0, 42},
+	}
+	if len(connections) != len(want) {
+		t.Fatalf("got %d connections, want %d", len(connections), len(want))
+	}
+	for i, c := range connections {
+		w := want[i]
+		if c.Local.String() != w.local || c.Remote.String() != w.remote || c.State != w.state ||
+			c.RxQueue != w.rxQueue || c.TxQueue != w.txQueue || c.Family != "ipv6" {
+			t.Errorf("connection %d = %s -> %s %s rx %d tx %d %s; want %s -> %s %s rx %d tx %d ipv6",
+				i, c.Local, c.Remote, c.State, c.RxQueue, c.TxQueue, c.Family, w.local, w.remote, w.state, w.rxQueue, w.txQueue)
+		}
+	}
+	// An IPv4-mapped address keeps its 16-byte form.
+	if ip := connections[2].Local.IP; len(ip) != net.IPv6len || !ip.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Errorf("mapped address = %#v", ip)
+	}
+}
+
+func TestReadSocketTableUDP(t *testing.T) {
+	path := writeProcFixture(t, "udp", ""+
+		"   sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops\n"+
+		"  100: 3500007F:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000   101        0 3001 2 0000000000000000 0\n"+
+		"  200: 1401A8C0:9C40 08080808:0035 01 00000000:00000340 00:00000000 00000000  1000        0 3002 2 0000000000000000 0\n")
+
+	connections, err := readSocketTable(socketTable{path, "udp", "ipv4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(connections) != 2 {
+		t.Fatalf("got %d connections, want 2", len(connections))
+	}
+	if c := connections[0]; c.State != "UNCONN" || c.Local.String() != "127.0.0.53:53" || c.UID != 101 || c.Inode != 3001 {
+		t.Errorf("unconnected socket = %+v", c)
+	}
+	if c := connections[1]; c.State != "ESTABLISHED" || c.Remote.String() != "8.8.8.8:53" || c.RxQueue != 0x340 {
+		t.Errorf("connected socket = %+v", c)
+	}
+}
+
+func TestReadSocketTableInvalidAddress(t *testing.T) {
+	for _, address := range []string{"0100007F", "XYZ0007F:0035", "0100:0035"} {
+		path := writeProcFixture(t, "tcp", socketTableHeader+
+			"   0: "+address+" 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1 1 0000000000000000 100 0 0 10 0\n")
+		if _, err := readSocketTable(socketTable{path, "tcp", "ipv4"}); err == nil {
+			t.Errorf("readSocketTable accepted the address %q", address)
+		}
+	}
+}
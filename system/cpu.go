@@ -1,11 +1,8 @@
 package system
 
 import (
-	"bufio"
-	"os"
+	"context"
 	"runtime"
-	"strconv"
-	"strings"
 	"time"
 )
 
@@ -13,75 +10,58 @@ import (
 type CPUInfo struct {
 	NumCores int
 	Usage    float64
+	Load     LoadAverage
 }
 
-// GetCPUInfo returns information about the server's processor, including the number of cores and the current CPU usage.
+// GetCPUInfo returns information about the server's processor, including the number of cores,
+// the current CPU usage and the load averages.
 func GetCPUInfo() CPUInfo {
+	load, _ := GetLoadAverage()
 	return CPUInfo{
 		NumCores: runtime.NumCPU(),
 		Usage:    calculateCPUUsage(),
+		Load:     load,
 	}
 }
 
-// calculateCPUUsage calculates the current CPU usage by taking multiple samples over a period of time and averaging them.
-func calculateCPUUsage() float64 {
-	const sampleCount = 5
-	var idleTotal, totalTotal uint64
-
-	for i := 0; i < sampleCount; i++ {
-		idle, total := readCPUTimes()
-		idleTotal += idle
-		totalTotal += total
-		time.Sleep(200 * time.Millisecond)
+// GetCPUInfoWith returns the number of cores, the load averages and the CPU usage measured over window.
+// It returns early with the context error if ctx is done before the window ends.
+func GetCPUInfoWith(ctx context.Context, window time.Duration) (CPUInfo, error) {
+	info := CPUInfo{NumCores: runtime.NumCPU()}
+	if load, err := GetLoadAverage(); err == nil {
+		info.Load = load
 	}
 
-	idleAvg := idleTotal / sampleCount
-	totalAvg := totalTotal / sampleCount
-
-	return computeUsage(idleAvg, totalAvg)
-}
-
-// readCPUTimes reads the /proc/stat file to get the CPU usage times.
-func readCPUTimes() (idle, total uint64) {
-	file, err := os.Open("/proc/stat")
+	sampler, err := NewCPUSampler()
 	if err != nil {
-		return 0, 0
+		return info, err
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "cpu ") {
-			return parseCPUTimes(strings.Fields(line))
-		}
+	timer := time.NewTimer(window)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return info, ctx.Err()
+	case <-timer.C:
 	}
-	return 0, 0
-}
 
-// parseCPUTimes parses the CPU times from the fields of the /proc/stat file.
-func parseCPUTimes(fields []string) (idle, total uint64) {
-	var times [7]uint64
-	for i := 1; i <= 7; i++ {
-		times[i-1], _ = strconv.ParseUint(fields[i], 10, 64)
+	sample, err := sampler.Sample()
+	if err != nil {
+		return info, err
 	}
-	idle = times[3]
-	total = sum(times[:])
-	return idle, total
+	info.Usage = sample.Total.Usage
+	return info, nil
 }
 
-// sum calculates the sum of a slice of uint64.
-func sum(values []uint64) uint64 {
-	var total uint64
-	for _, value := range values {
-		total += value
+// calculateCPUUsage calculates the CPU usage over one second from the difference between two readings of /proc/stat.
+func calculateCPUUsage() float64 {
+	sampler, err := NewCPUSampler()
+	if err != nil {
+		return 0
 	}
-	return total
-}
-
-// computeUsage calculates the CPU usage percentage.
-func computeUsage(idle, total uint64) float64 {
-	idleTicks := float64(idle)
-	totalTicks := float64(total)
-	return 100 * (1 - idleTicks/totalTicks)
+	time.Sleep(time.Second)
+	sample, err := sampler.Sample()
+	if err != nil {
+		return 0
+	}
+	return sample.Total.Usage
 }
@@ -0,0 +1,214 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CPUModes holds a value for each CPU mode reported in /proc/stat.
+// In CPUCoreUsage the values are percentages of the time elapsed between two samples.
+// Guest and GuestNice are already included in User and Nice, as the kernel reports them.
+type CPUModes struct {
+	User      float64
+	Nice      float64
+	System    float64
+	Idle      float64
+	IOWait    float64
+	IRQ       float64
+	SoftIRQ   float64
+	Steal     float64
+	Guest     float64
+	GuestNice float64
+}
+
+// CPUCoreUsage is the usage of one CPU, or of all of them, between two samples.
+type CPUCoreUsage struct {
+	Name  string   // "cpu" for the aggregate of all CPUs, "cpu0", "cpu1"... for each core.
+	Usage float64  // Percentage of time not spent idle or waiting for IO.
+	Modes CPUModes // Percentage of time spent in each mode.
+}
+
+// CPUSample is the CPU activity between two readings of /proc/stat.
+// A core that came online between the two readings is left out of Cores until the next sample.
+type CPUSample struct {
+	Interval              time.Duration
+	Total                 CPUCoreUsage
+	Cores                 []CPUCoreUsage
+	ContextSwitchesPerSec float64
+	InterruptsPerSec      float64
+	ProcessesPerSec       float64 // Processes and threads created per second.
+	ProcsRunning          uint64  // Processes currently runnable.
+	ProcsBlocked          uint64  // Processes currently blocked waiting for IO.
+}
+
+// cpuTicks holds the cumulative ticks of one cpu line of /proc/stat, in the order of the file.
+type cpuTicks [10]uint64
+
+// procStat is a snapshot of the counters in /proc/stat.
+type procStat struct {
+	time            time.Time
+	total           cpuTicks
+	cores           map[string]cpuTicks
+	coreNames       []string
+	contextSwitches uint64
+	interrupts      uint64
+	processes       uint64
+	procsRunning    uint64
+	procsBlocked    uint64
+}
+
+// CPUSampler computes CPU usage from the difference between consecutive readings of /proc/stat.
+// It keeps the previous reading, so Sample returns immediately instead of sleeping;
+// the sample covers the time since the previous call. It is safe for concurrent use: each call
+// reads /proc/stat and replaces the previous reading under one lock, so concurrent calls split
+// the time between them instead of going back in time.
+type CPUSampler struct {
+	mu   sync.Mutex
+	prev procStat
+}
+
+// NewCPUSampler returns a sampler with a first reading of /proc/stat.
+func NewCPUSampler() (*CPUSampler, error) {
+	stat, err := readProcStat()
+	if err != nil {
+		return nil, err
+	}
+	return &CPUSampler{prev: stat}, nil
+}
+
+// Sample returns the CPU activity since the previous call, or since NewCPUSampler for the first one.
+func (s *CPUSampler) Sample() (CPUSample, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stat, err := readProcStat()
+	if err != nil {
+		return CPUSample{}, err
+	}
+	prev := s.prev
+	s.prev = stat
+	return diffProcStat(prev, stat), nil
+}
+
+// diffProcStat computes the activity between two snapshots.
+func diffProcStat(prev, cur procStat) CPUSample {
+	interval := cur.time.Sub(prev.time)
+	sample := CPUSample{
+		Interval:     interval,
+		Total:        coreUsage("cpu", prev.total, cur.total),
+		ProcsRunning: cur.procsRunning,
+		ProcsBlocked: cur.procsBlocked,
+	}
+	for _, name := range cur.coreNames {
+		// The ticks of a core that came online since the previous reading cover the time since boot.
+		before, ok := prev.cores[name]
+		if !ok {
+			continue
+		}
+		sample.Cores = append(sample.Cores, coreUsage(name, before, cur.cores[name]))
+	}
+	if seconds := interval.Seconds(); seconds > 0 {
+		sample.ContextSwitchesPerSec = float64(counterDelta(prev.contextSwitches, cur.contextSwitches)) / seconds
+		sample.InterruptsPerSec = float64(counterDelta(prev.interrupts, cur.interrupts)) / seconds
+		sample.ProcessesPerSec = float64(counterDelta(prev.processes, cur.processes)) / seconds
+	}
+	return sample
+}
+
+// coreUsage converts the tick deltas of one CPU into percentages.
+func coreUsage(name string, prev, cur cpuTicks) CPUCoreUsage {
+	var delta cpuTicks
+	for i := range cur {
+		delta[i] = counterDelta(prev[i], cur[i])
+	}
+	// Guest time is already counted in user and nice, so it is left out of the total.
+	var total uint64
+	for _, ticks := range delta[:8] {
+		total += ticks
+	}
+
+	usage := CPUCoreUsage{Name: name}
+	if total == 0 {
+		return usage
+	}
+	percent := func(ticks uint64) float64 {
+		return 100 * float64(ticks) / float64(total)
+	}
+	usage.Modes = CPUModes{
+		User:      percent(delta[0]),
+		Nice:      percent(delta[1]),
+		System:    percent(delta[2]),
+		Idle:      percent(delta[3]),
+		IOWait:    percent(delta[4]),
+		IRQ:       percent(delta[5]),
+		SoftIRQ:   percent(delta[6]),
+		Steal:     percent(delta[7]),
+		Guest:     percent(delta[8]),
+		GuestNice: percent(delta[9]),
+	}
+	usage.Usage = 100 - usage.Modes.Idle - usage.Modes.IOWait
+	return usage
+}
+
+// counterDelta returns cur-prev, or zero if the counter went backwards (e.g. a core was reset).
+func counterDelta(prev, cur uint64) uint64 {
+	if cur < prev {
+		return 0
+	}
+	return cur - prev
+}
+
+// readProcStat reads the CPU counters from /proc/stat.
+func readProcStat() (procStat, error) {
+	file, err := os.Open("/proc/stat")
+	if err != nil {
+		return procStat{}, err
+	}
+	defer file.Close()
+
+	stat := procStat{time: time.Now(), cores: make(map[string]cpuTicks)}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // The intr line lists every interrupt and can be long.
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch key := fields[0]; {
+		case key == "cpu":
+			stat.total = parseCPUTicks(fields[1:])
+		case strings.HasPrefix(key, "cpu"):
+			stat.cores[key] = parseCPUTicks(fields[1:])
+			stat.coreNames = append(stat.coreNames, key)
+		case key == "ctxt":
+			stat.contextSwitches, _ = strconv.ParseUint(fields[1], 10, 64)
+		case key == "intr":
+			stat.interrupts, _ = strconv.ParseUint(fields[1], 10, 64)
+		case key == "processes":
+			stat.processes, _ = strconv.ParseUint(fields[1], 10, 64)
+		case key == "procs_running":
+			stat.procsRunning, _ = strconv.ParseUint(fields[1], 10, 64)
+		case key == "procs_blocked":
+			stat.procsBlocked, _ = strconv.ParseUint(fields[1], 10, 64)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return procStat{}, fmt.Errorf("error reading /proc/stat: %v", err)
+	}
+	return stat, nil
+}
+
+// parseCPUTicks parses the tick columns of a cpu line. Older kernels report fewer columns;
+// the missing ones are left at zero.
+func parseCPUTicks(fields []string) cpuTicks {
+	var ticks cpuTicks
+	for i := 0; i < len(fields) && i < len(ticks); i++ {
+		ticks[i], _ = strconv.ParseUint(fields[i], 10, 64)
+	}
+	return ticks
+}
@@ -0,0 +1,68 @@
+package system
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCPUSamplerConcurrentIntervals(t *testing.T) {
+	sampler, err := NewCPUSampler()
+	if err != nil {
+		t.Skip("/proc/stat is not available:", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				sample, err := sampler.Sample()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if sample.Interval < 0 {
+					t.Errorf("negative interval %v", sample.Interval)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDiffProcStatSkipsCoreBroughtOnline(t *testing.T) {
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	prev := procStat{
+		time:      start,
+		total:     cpuTicks{100, 0, 100, 800},
+		cores:     map[string]cpuTicks{"cpu0": {100, 0, 100, 800}},
+		coreNames: []string{"cpu0"},
+	}
+	// cpu1 came online with the ticks it accumulated since boot, all of them busy.
+	cur := procStat{
+		time:      start.Add(time.Second),
+		total:     cpuTicks{150, 0, 150, 900, 0, 0, 0, 0},
+		cores:     map[string]cpuTicks{"cpu0": {150, 0, 100, 850}, "cpu1": {5000, 0, 5000, 0}},
+		coreNames: []string{"cpu0", "cpu1"},
+	}
+
+	sample := diffProcStat(prev, cur)
+	if len(sample.Cores) != 1 || sample.Cores[0].Name != "cpu0" {
+		t.Fatalf("cores = %+v, want cpu0 only", sample.Cores)
+	}
+	if got := sample.Cores[0].Usage; got != 50 {
+		t.Errorf("cpu0 usage = %v, want 50", got)
+	}
+
+	// In the next sample cpu1 is measured from the reading where it appeared.
+	next := cur
+	next.time = cur.time.Add(time.Second)
+	next.cores = map[string]cpuTicks{"cpu0": {150, 0, 100, 950}, "cpu1": {5025, 0, 5000, 75}}
+	sample = diffProcStat(cur, next)
+	if len(sample.Cores) != 2 || sample.Cores[1].Usage != 25 {
+		t.Errorf("cores = %+v, want cpu1 at 25%%", sample.Cores)
+	}
+}
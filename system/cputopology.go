@@ -0,0 +1,344 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const cpuSysfsPath = "/sys/devices/system/cpu"
+
+// CPUCache describes one processor cache. Caches shared by several CPUs are listed once.
+type CPUCache struct {
+	Level      int
+	Type       string // "Data", "Instruction" or "Unified".
+	Size       uint64 // Size in bytes.
+	SharedCPUs []int  // Logical CPUs that share the cache.
+}
+
+// LogicalCPU describes one logical CPU (a hardware thread).
+// Frequencies are in MHz and are zero when cpufreq is not available, e.g. in most virtual machines.
+type LogicalCPU struct {
+	ID         int
+	Online     bool
+	SocketID   int
+	CoreID     int
+	Siblings   []int // Logical CPUs on the same physical core, including this one.
+	CurrentMHz float64
+	MinMHz     float64
+	MaxMHz     float64
+	Governor   string
+}
+
+// CPUTopology describes the processors of the machine, as reported by /proc/cpuinfo and /sys/devices/system/cpu.
+type CPUTopology struct {
+	Vendor          string
+	ModelName       string
+	Family          string
+	Model           string
+	Stepping        string
+	Microcode       string
+	Sockets         int // Physical processor packages.
+	Cores           int // Physical cores across all sockets.
+	Threads         int // Online logical CPUs.
+	ThreadsPerCore  int
+	Caches          []CPUCache
+	CPUs            []LogicalCPU
+	Flags           []string          // Feature flags, such as "avx2" or "aes".
+	Vulnerabilities map[string]string // Mitigation status by vulnerability name, such as "spectre_v2".
+	Online          []int
+	Offline         []int
+	Present         []int
+	Possible        []int
+}
+
+// HasFlag reports whether the processor supports a feature flag, such as "avx2".
+func (t CPUTopology) HasFlag(flag string) bool {
+	for _, f := range t.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCPUTopology collects the processor topology, frequencies, caches, flags and vulnerabilities.
+func GetCPUTopology() (CPUTopology, error) {
+	var topology CPUTopology
+	cpuinfoMHz, err := parseCPUInfo("/proc/cpuinfo", &topology)
+	if err != nil {
+		return CPUTopology{}, err
+	}
+
+	topology.Online, _ = readCPUList(filepath.Join(cpuSysfsPath, "online"))
+	topology.Offline, _ = readCPUList(filepath.Join(cpuSysfsPath, "offline"))
+	topology.Present, _ = readCPUList(filepath.Join(cpuSysfsPath, "present"))
+	topology.Possible, _ = readCPUList(filepath.Join(cpuSysfsPath, "possible"))
+	online := make(map[int]bool, len(topology.Online))
+	for _, id := range topology.Online {
+		online[id] = true
+	}
+	// Kernels without CPU hotplug do not provide the online file; every CPU is online then.
+	allOnline := len(topology.Online) == 0
+
+	ids := topology.Present
+	if len(ids) == 0 {
+		// Without sysfs, fall back to the processors listed in /proc/cpuinfo, which are the online ones.
+		for id := range cpuinfoMHz {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+	}
+
+	sockets := make(map[int]bool)
+	cores := make(map[[2]int]bool)
+	caches := make(map[string]CPUCache)
+	for _, id := range ids {
+		cpu := readLogicalCPU(id, allOnline || online[id])
+		if cpu.CurrentMHz == 0 {
+			cpu.CurrentMHz = cpuinfoMHz[id]
+		}
+		topology.CPUs = append(topology.CPUs, cpu)
+		if !cpu.Online {
+			continue
+		}
+		topology.Threads++
+		sockets[cpu.SocketID] = true
+		cores[[2]int{cpu.SocketID, cpu.CoreID}] = true
+		for _, cache := range readCPUCaches(id) {
+			key := fmt.Sprintf("%d/%s/%v", cache.Level, cache.Type, cache.SharedCPUs)
+			caches[key] = cache
+		}
+	}
+	topology.Sockets = len(sockets)
+	topology.Cores = len(cores)
+	if topology.Cores > 0 {
+		topology.ThreadsPerCore = topology.Threads / topology.Cores
+	}
+
+	for _, cache := range caches {
+		topology.Caches = append(topology.Caches, cache)
+	}
+	sort.Slice(topology.Caches, func(i, j int) bool {
+		a, b := topology.Caches[i], topology.Caches[j]
+		if a.Level != b.Level {
+			return a.Level < b.Level
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return firstCPU(a.SharedCPUs) < firstCPU(b.SharedCPUs)
+	})
+
+	topology.Vulnerabilities = readVulnerabilities()
+	return topology, nil
+}
+
+// parseCPUInfo fills the processor identification and flags from a file in the format of
+// /proc/cpuinfo and returns the "cpu MHz" value of each processor, used when cpufreq is not available.
+func parseCPUInfo(path string, topology *CPUTopology) (map[int]float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	mhz := make(map[int]float64)
+	processor := -1
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // The flags line is long.
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "processor":
+			processor, _ = strconv.Atoi(value)
+		case "vendor_id", "CPU implementer":
+			setOnce(&topology.Vendor, value)
+		case "model name", "Processor":
+			setOnce(&topology.ModelName, value)
+		case "cpu family", "CPU architecture":
+			setOnce(&topology.Family, value)
+		case "model", "CPU part":
+			setOnce(&topology.Model, value)
+		case "stepping", "CPU revision":
+			setOnce(&topology.Stepping, value)
+		case "microcode":
+			setOnce(&topology.Microcode, value)
+		case "cpu MHz":
+			if processor >= 0 {
+				mhz[processor], _ = strconv.ParseFloat(value, 64)
+			}
+		case "flags", "Features":
+			if topology.Flags == nil {
+				topology.Flags = strings.Fields(value)
+			}
+		}
+		if processor >= 0 {
+			if _, ok := mhz[processor]; !ok {
+				mhz[processor] = 0
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading /proc/cpuinfo: %v", err)
+	}
+	return mhz, nil
+}
+
+// setOnce sets a string the first time a value is found, since /proc/cpuinfo repeats it for every processor.
+func setOnce(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
+
+// readLogicalCPU reads the topology and frequency of a logical CPU from sysfs.
+func readLogicalCPU(id int, online bool) LogicalCPU {
+	dir := filepath.Join(cpuSysfsPath, "cpu"+strconv.Itoa(id))
+	cpu := LogicalCPU{ID: id, Online: online}
+	if !online {
+		return cpu
+	}
+
+	cpu.SocketID, _ = readSysfsInt(filepath.Join(dir, "topology", "physical_package_id"))
+	cpu.CoreID, _ = readSysfsInt(filepath.Join(dir, "topology", "core_id"))
+	cpu.Siblings, _ = readCPUList(filepath.Join(dir, "topology", "thread_siblings_list"))
+
+	if khz, err := readSysfsInt(filepath.Join(dir, "cpufreq", "scaling_cur_freq")); err == nil {
+		cpu.CurrentMHz = float64(khz) / 1000
+	}
+	if khz, err := readSysfsInt(filepath.Join(dir, "cpufreq", "cpuinfo_min_freq")); err == nil {
+		cpu.MinMHz = float64(khz) / 1000
+	}
+	if khz, err := readSysfsInt(filepath.Join(dir, "cpufreq", "cpuinfo_max_freq")); err == nil {
+		cpu.MaxMHz = float64(khz) / 1000
+	}
+	cpu.Governor, _ = readSysfsString(filepath.Join(dir, "cpufreq", "scaling_governor"))
+	return cpu
+}
+
+// readCPUCaches reads the caches of a logical CPU from sysfs.
+func readCPUCaches(id int) []CPUCache {
+	dirs, _ := filepath.Glob(filepath.Join(cpuSysfsPath, "cpu"+strconv.Itoa(id), "cache", "index*"))
+	var caches []CPUCache
+	for _, dir := range dirs {
+		level, err := readSysfsInt(filepath.Join(dir, "level"))
+		if err != nil {
+			continue
+		}
+		cacheType, _ := readSysfsString(filepath.Join(dir, "type"))
+		size, _ := readSysfsString(filepath.Join(dir, "size"))
+		shared, _ := readCPUList(filepath.Join(dir, "shared_cpu_list"))
+		caches = append(caches, CPUCache{
+			Level:      level,
+			Type:       cacheType,
+			Size:       parseSizeSuffix(size),
+			SharedCPUs: shared,
+		})
+	}
+	return caches
+}
+
+// readVulnerabilities reads the mitigation status of every known CPU vulnerability.
+func readVulnerabilities() map[string]string {
+	files, _ := filepath.Glob(filepath.Join(cpuSysfsPath, "vulnerabilities", "*"))
+	if len(files) == 0 {
+		return nil
+	}
+	vulnerabilities := make(map[string]string, len(files))
+	for _, file := range files {
+		if status, err := readSysfsString(file); err == nil {
+			vulnerabilities[filepath.Base(file)] = status
+		}
+	}
+	return vulnerabilities
+}
+
+// readCPUList reads a sysfs file holding a CPU list such as "0-3,5,7-8".
+func readCPUList(path string) ([]int, error) {
+	value, err := readSysfsString(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseCPUList(value)
+}
+
+// parseCPUList expands a CPU list such as "0-3,5,7-8" into the CPU numbers.
+func parseCPUList(list string) ([]int, error) {
+	var cpus []int
+	for _, part := range strings.Split(strings.TrimSpace(list), ",") {
+		if part == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CPU list %q: %v", list, err)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil {
+				return nil, fmt.Errorf("invalid CPU list %q: %v", list, err)
+			}
+			if end < start {
+				return nil, fmt.Errorf("invalid CPU list %q: reversed range %s", list, part)
+			}
+		}
+		for cpu := start; cpu <= end; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
+
+// parseSizeSuffix parses sizes such as "32K" or "8192K" used by sysfs, returning bytes.
+func parseSizeSuffix(value string) uint64 {
+	value = strings.TrimSpace(value)
+	multiplier := uint64(1)
+	switch {
+	case strings.HasSuffix(value, "K"):
+		multiplier = 1024
+	case strings.HasSuffix(value, "M"):
+		multiplier = 1024 * 1024
+	case strings.HasSuffix(value, "G"):
+		multiplier = 1024 * 1024 * 1024
+	}
+	n, _ := strconv.ParseUint(strings.TrimRight(value, "KMG"), 10, 64)
+	return n * multiplier
+}
+
+// firstCPU returns the lowest CPU of a list, or -1 for an empty list.
+func firstCPU(cpus []int) int {
+	if len(cpus) == 0 {
+		return -1
+	}
+	return cpus[0]
+}
+
+// readSysfsString reads a sysfs attribute without the trailing newline.
+func readSysfsString(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// readSysfsInt reads a sysfs attribute holding an integer.
+func readSysfsInt(path string) (int, error) {
+	value, err := readSysfsString(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(value)
+}
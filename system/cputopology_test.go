@@ -0,0 +1,133 @@
+package system
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCPUList(t *testing.T) {
+	tests := []struct {
+		list    string
+		want    []int
+		wantErr bool
+	}{
+		{"0-3,5,7-8", []int{0, 1, 2, 3, 5, 7, 8}, false},
+		{"0", []int{0}, false},
+		{"4-4\n", []int{4}, false},
+		// The offline file of a machine with every CPU online is empty.
+		{"", nil, false},
+		{"\n", nil, false},
+		{"0-", nil, true},
+		{"-3", nil, true},
+		{"0-3,a", nil, true},
+		{"1-2-3", nil, true},
+		{"5-3", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseCPUList(tt.list)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseCPUList(%q) error = %v, want error %v", tt.list, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseCPUList(%q) = %v, want %v", tt.list, got, tt.want)
+		}
+	}
+}
+
+func TestParseSizeSuffix(t *testing.T) {
+	tests := []struct {
+		value string
+		want  uint64
+	}{
+		{"32K", 32 * 1024},
+		{"8M", 8 * 1024 * 1024},
+		{"1G", 1024 * 1024 * 1024},
+		{"8192K\n", 8192 * 1024},
+		{"512", 512},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := parseSizeSuffix(tt.value); got != tt.want {
+			t.Errorf("parseSizeSuffix(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseCPUInfo(t *testing.T) {
+	path := writeProcFixture(t, "cpuinfo", ""+
+		"processor\t: 0\n"+
+		"vendor_id\t: GenuineIntel\n"+
+		"cpu family\t: 6\n"+
+		"model\t\t: 140\n"+
+		"model name\t: 11th Gen Intel(R) Core(TM) i7-1165G7 @ 2.80GHz\n"+
+		"stepping\t: 1\n"+
+		"microcode\t: 0xb4\n"+
+		"cpu MHz\t\t: 1190.400\n"+
+		"flags\t\t: fpu vme sse2 avx2 aes\n"+
+		"\n"+
+		"processor\t: 1\n"+
+		"vendor_id\t: GenuineIntel\n"+
+		"model name\t: 11th Gen Intel(R) Core(TM) i7-1165G7 @ 2.80GHz\n"+
+		"cpu MHz\t\t: 2800.000\n"+
+		"flags\t\t: fpu vme sse2 avx2 aes\n"+
+		"\n"+
+		// A processor without a "cpu MHz" line still gets an entry.
+		"processor\t: 2\n"+
+		"vendor_id\t: GenuineIntel\n")
+
+	var topology CPUTopology
+	mhz, err := parseCPUInfo(path, &topology)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := CPUTopology{
+		Vendor:    "GenuineIntel",
+		ModelName: "11th Gen Intel(R) Core(TM) i7-1165G7 @ 2.80GHz",
+		Family:    "6",
+		Model:     "140",
+		Stepping:  "1",
+		Microcode: "0xb4",
+		Flags:     []string{"fpu", "vme", "sse2", "avx2", "aes"},
+	}
+	if !reflect.DeepEqual(topology, want) {
+		t.Errorf("topology = %+v, want %+v", topology, want)
+	}
+	if wantMHz := map[int]float64{0: 1190.4, 1: 2800, 2: 0}; !reflect.DeepEqual(mhz, wantMHz) {
+		t.Errorf("MHz = %v, want %v", mhz, wantMHz)
+	}
+}
+
+func TestParseCPUInfoARM(t *testing.T) {
+	path := writeProcFixture(t, "cpuinfo", ""+
+		"processor\t: 0\n"+
+		"BogoMIPS\t: 108.00\n"+
+		"Features\t: fp asimd evtstrm crc32 cpuid\n"+
+		"CPU implementer\t: 0x41\n"+
+		"CPU architecture: 8\n"+
+		"CPU variant\t: 0x0\n"+
+		"CPU part\t: 0xd08\n"+
+		"CPU revision\t: 3\n"+
+		"\n"+
+		"Hardware\t: BCM2835\n"+
+		"Model\t\t: Raspberry Pi 4 Model B Rev 1.4\n")
+
+	var topology CPUTopology
+	mhz, err := parseCPUInfo(path, &topology)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := CPUTopology{
+		Vendor:   "0x41",
+		Family:   "8",
+		Model:    "0xd08",
+		Stepping: "3",
+		Flags:    []string{"fp", "asimd", "evtstrm", "crc32", "cpuid"},
+	}
+	if !reflect.DeepEqual(topology, want) {
+		t.Errorf("topology = %+v, want %+v", topology, want)
+	}
+	if wantMHz := map[int]float64{0: 0}; !reflect.DeepEqual(mhz, wantMHz) {
+		t.Errorf("MHz = %v, want %v", mhz, wantMHz)
+	}
+}
@@ -0,0 +1,278 @@
+package system
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// diskSectorSize is the unit of the sector counters in /proc/diskstats, whatever the device's sector size.
+const diskSectorSize = 512
+
+// DiskIOStats is the activity of one block device or partition between two samples.
+type DiskIOStats struct {
+	Name             string // Kernel name, e.g. "sda", "sda1" or "nvme0n1".
+	Major            int
+	Minor            int
+	Partition        bool
+	ReadsPerSec      float64 // Read operations completed per second.
+	WritesPerSec     float64 // Write operations completed per second.
+	ReadBytesPerSec  float64
+	WriteBytesPerSec float64
+	ReadLatency      time.Duration // Average time of the read operations completed in the interval.
+	WriteLatency     time.Duration // Average time of the write operations completed in the interval.
+	QueueDepth       float64       // Average number of operations in flight, like avgqu-sz in iostat.
+	InFlight         uint64        // Operations in flight when the sample was taken.
+	Utilization      float64       // Percentage of the interval in which the device was busy, like %util in iostat.
+}
+
+// DiskIOSample is the disk activity between two readings of /proc/diskstats.
+// A device attached between the two readings is left out until the next sample.
+type DiskIOSample struct {
+	Interval time.Duration
+	Devices  []DiskIOStats
+}
+
+// diskCounters holds the cumulative counters of one line of /proc/diskstats.
+type diskCounters struct {
+	major, minor   int
+	reads          uint64
+	sectorsRead    uint64
+	readTicks      uint64 // Milliseconds.
+	writes         uint64
+	sectorsWritten uint64
+	writeTicks     uint64 // Milliseconds.
+	inFlight       uint64
+	ioTicks        uint64 // Milliseconds.
+	queueTicks     uint64 // Weighted milliseconds.
+}
+
+// diskStats is a snapshot of /proc/diskstats.
+type diskStats struct {
+	time    time.Time
+	names   []string
+	devices map[string]diskCounters
+}
+
+// DiskIOSampler computes disk IO rates from the difference between consecutive readings of
+// /proc/diskstats. Sample returns immediately and covers the time since the previous call.
+// Concurrent calls to Sample are serialized, each one covering the time since the one before it.
+type DiskIOSampler struct {
+	mu   sync.Mutex
+	prev diskStats
+}
+
+// NewDiskIOSampler returns a sampler with a first reading of /proc/diskstats.
+func NewDiskIOSampler() (*DiskIOSampler, error) {
+	stats, err := readDiskStats()
+	if err != nil {
+		return nil, err
+	}
+	return &DiskIOSampler{prev: stats}, nil
+}
+
+// Sample returns the disk activity since the previous call, or since NewDiskIOSampler for the first one.
+func (s *DiskIOSampler) Sample() (DiskIOSample, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats, err := readDiskStats()
+	if err != nil {
+		return DiskIOSample{}, err
+	}
+	prev := s.prev
+	s.prev = stats
+	return diffDiskStats(prev, stats), nil
+}
+
+// diffDiskStats computes the activity between two snapshots.
+func diffDiskStats(prev, cur diskStats) DiskIOSample {
+	interval := cur.time.Sub(prev.time)
+	sample := DiskIOSample{Interval: interval}
+	seconds := interval.Seconds()
+	milliseconds := float64(interval.Milliseconds())
+
+	for _, name := range cur.names {
+		// The counters of a device attached since the previous reading cover the time since boot.
+		before, ok := prev.devices[name]
+		if !ok {
+			continue
+		}
+		now := cur.devices[name]
+		stats := DiskIOStats{
+			Name:      name,
+			Major:     now.major,
+			Minor:     now.minor,
+			Partition: isPartition(name),
+			InFlight:  now.inFlight,
+		}
+		reads := counterDelta(before.reads, now.reads)
+		writes := counterDelta(before.writes, now.writes)
+		if reads > 0 {
+			stats.ReadLatency = time.Duration(counterDelta(before.readTicks, now.readTicks)) * time.Millisecond / time.Duration(reads)
+		}
+		if writes > 0 {
+			stats.WriteLatency = time.Duration(counterDelta(before.writeTicks, now.writeTicks)) * time.Millisecond / time.Duration(writes)
+		}
+		if seconds > 0 {
+			stats.ReadsPerSec = float64(reads) / seconds
+			stats.WritesPerSec = float64(writes) / seconds
+			stats.ReadBytesPerSec = float64(counterDelta(before.sectorsRead, now.sectorsRead)*diskSectorSize) / seconds
+			stats.WriteBytesPerSec = float64(counterDelta(before.sectorsWritten, now.sectorsWritten)*diskSectorSize) / seconds
+		}
+		if milliseconds > 0 {
+			stats.QueueDepth = float64(counterDelta(before.queueTicks, now.queueTicks)) / milliseconds
+			stats.Utilization = min(100, 100*float64(counterDelta(before.ioTicks, now.ioTicks))/milliseconds)
+		}
+		sample.Devices = append(sample.Devices, stats)
+	}
+	return sample
+}
+
+// isPartition reports whether a block device is a partition.
+func isPartition(name string) bool {
+	return fileExists(filepath.Join("/sys/class/block", name, "partition"))
+}
+
+// readDiskStats reads the counters from /proc/diskstats. Lines look like
+// "8 0 sda 1520 12 98342 803 2014 1533 61234 2213 0 2120 3016 ..."; kernels since 4.18 and 5.5
+// append discard and flush counters, which are not used.
+func readDiskStats() (diskStats, error) {
+	file, err := os.Open("/proc/diskstats")
+	if err != nil {
+		return diskStats{}, err
+	}
+	defer file.Close()
+
+	stats := diskStats{time: time.Now(), devices: make(map[string]diskCounters)}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 14 {
+			continue
+		}
+		var values [11]uint64
+		for i := range values {
+			values[i], _ = strconv.ParseUint(fields[3+i], 10, 64)
+		}
+		major, _ := strconv.Atoi(fields[0])
+		minor, _ := strconv.Atoi(fields[1])
+		name := fields[2]
+		stats.names = append(stats.names, name)
+		stats.devices[name] = diskCounters{
+			major:          major,
+			minor:          minor,
+			reads:          values[0],
+			sectorsRead:    values[2],
+			readTicks:      values[3],
+			writes:         values[4],
+			sectorsWritten: values[6],
+			writeTicks:     values[7],
+			inFlight:       values[8],
+			ioTicks:        values[9],
+			queueTicks:     values[10],
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return diskStats{}, fmt.Errorf("error reading /proc/diskstats: %v", err)
+	}
+	return stats, nil
+}
+
+// DiskMonitorOptions configures MonitorDiskIO.
+type DiskMonitorOptions struct {
+	Interval       time.Duration // Time between samples. Defaults to 1s.
+	Devices        []string      // Devices to report, e.g. "sda" or "nvme0n1". Empty reports all of them.
+	SkipPartitions bool          // Report whole devices only.
+}
+
+// MonitorDiskIO sends a DiskIOSample every interval until ctx is done, when both channels are closed.
+// Read errors are sent on the error channel without stopping the monitor; an error is dropped
+// while the previous one is unread.
+func MonitorDiskIO(ctx context.Context, opts DiskMonitorOptions) (<-chan DiskIOSample, <-chan error) {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultSampleWindow
+	}
+	samples := make(chan DiskIOSample)
+	errs := make(chan error, 1)
+	report := func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}
+
+	go func() {
+		defer close(samples)
+		defer close(errs)
+
+		sampler, err := NewDiskIOSampler()
+		if err != nil {
+			report(err)
+		}
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if sampler == nil {
+				// The first reading failed; start over from this tick.
+				if sampler, err = NewDiskIOSampler(); err != nil {
+					report(err)
+				}
+				continue
+			}
+			sample, err := sampler.Sample()
+			if err != nil {
+				report(err)
+				continue
+			}
+			sample.Devices = opts.filter(sample.Devices)
+
+			select {
+			case samples <- sample:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return samples, errs
+}
+
+// filter keeps the devices selected by the options.
+func (o DiskMonitorOptions) filter(devices []DiskIOStats) []DiskIOStats {
+	if len(o.Devices) == 0 && !o.SkipPartitions {
+		return devices
+	}
+	var selected []DiskIOStats
+	for _, device := range devices {
+		if o.SkipPartitions && device.Partition {
+			continue
+		}
+		if len(o.Devices) > 0 && !containsString(o.Devices, device.Name) {
+			continue
+		}
+		selected = append(selected, device)
+	}
+	return selected
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package system
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiffDiskStatsSkipsNewDevices(t *testing.T) {
+	start := time.Now()
+	prev := diskStats{
+		time:    start,
+		names:   []string{"sda"},
+		devices: map[string]diskCounters{"sda": {reads: 100, ioTicks: 1000}},
+	}
+	cur := diskStats{
+		time:  start.Add(time.Second),
+		names: []string{"sda", "sdb"},
+		devices: map[string]diskCounters{
+			"sda": {reads: 150, ioTicks: 1500},
+			"sdb": {reads: 1_000_000, ioTicks: 50_000_000},
+		},
+	}
+
+	sample := diffDiskStats(prev, cur)
+	if len(sample.Devices) != 1 || sample.Devices[0].Name != "sda" {
+		t.Fatalf("devices = %+v, want only sda", sample.Devices)
+	}
+	if got := sample.Devices[0]; got.ReadsPerSec != 50 || got.Utilization != 50 {
+		t.Errorf("sda reads = %v/s, utilization = %v%%, want 50/s and 50%%", got.ReadsPerSec, got.Utilization)
+	}
+}
@@ -0,0 +1,120 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ResolvConf is the resolver configuration from /etc/resolv.conf.
+type ResolvConf struct {
+	Nameservers []string
+	Domain      string   // Local domain name, used when Search is empty.
+	Search      []string // Domains appended to names without enough dots.
+	SortList    []string
+	Options     []string // e.g. "ndots:5", "timeout:2", "rotate" or "edns0".
+}
+
+// GetResolvConf reads the resolver configuration from /etc/resolv.conf.
+func GetResolvConf() (ResolvConf, error) {
+	return ReadResolvConf("/etc/resolv.conf")
+}
+
+// ReadResolvConf reads a resolver configuration file. As in the C library, the last domain
+// or search line wins, and lines starting with '#' or ';' are comments.
+func ReadResolvConf(path string) (ResolvConf, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return ResolvConf{}, err
+	}
+	defer file.Close()
+
+	var conf ResolvConf
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(stripComment(scanner.Text(), "#;"))
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			conf.Nameservers = append(conf.Nameservers, fields[1])
+		case "domain":
+			conf.Domain = fields[1]
+			conf.Search = nil
+		case "search":
+			conf.Search = fields[1:]
+			conf.Domain = ""
+		case "sortlist":
+			conf.SortList = fields[1:]
+		case "options":
+			conf.Options = append(conf.Options, fields[1:]...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return ResolvConf{}, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return conf, nil
+}
+
+// HostEntry is a line of a hosts file: an address and its names. The first name is the canonical one.
+type HostEntry struct {
+	IP        string
+	Hostnames []string
+	Comment   string // Comment at the end of the line, without the '#'.
+}
+
+// GetHosts reads the entries of /etc/hosts.
+func GetHosts() ([]HostEntry, error) {
+	return ReadHosts("/etc/hosts")
+}
+
+// ReadHosts reads the entries of a hosts file, skipping comments and blank lines.
+func ReadHosts(path string) ([]HostEntry, error) {
+	hosts, err := OpenHostsFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return hosts.Entries(), nil
+}
+
+// NSSwitch maps each database of /etc/nsswitch.conf, such as "hosts" or "passwd", to its sources
+// in lookup order. Actions such as "[NOTFOUND=return]" are kept in place among the sources.
+type NSSwitch map[string][]string
+
+// GetNSSwitch reads the name service switch configuration from /etc/nsswitch.conf.
+func GetNSSwitch() (NSSwitch, error) {
+	return ReadNSSwitch("/etc/nsswitch.conf")
+}
+
+// ReadNSSwitch reads a name service switch configuration file.
+func ReadNSSwitch(path string) (NSSwitch, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	nsswitch := make(NSSwitch)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		database, sources, ok := strings.Cut(stripComment(scanner.Text(), "#"), ":")
+		if !ok {
+			continue
+		}
+		nsswitch[strings.TrimSpace(database)] = strings.Fields(sources)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return nsswitch, nil
+}
+
+// stripComment removes the text from the first of the comment characters to the end of the line.
+func stripComment(line, commentChars string) string {
+	if i := strings.IndexAny(line, commentChars); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
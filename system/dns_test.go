@@ -0,0 +1,95 @@
+package system
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadResolvConf(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    ResolvConf
+	}{
+		{"systemd-resolved stub", "" +
+			"# This is /run/systemd/resolve/stub-resolv.conf managed by man:systemd-resolved(8).\n" +
+			"nameserver 127.0.0.53\n" +
+			"options edns0 trust-ad\n" +
+			"search corp.example.com example.com\n",
+			ResolvConf{Nameservers: []string{"127.0.0.53"}, Search: []string{"corp.example.com", "example.com"},
+				Options: []string{"edns0", "trust-ad"}}},
+		{"comments and several options lines", "" +
+			"; written by dhclient\n" +
+			"nameserver 10.0.0.2 # primary\n" +
+			"nameserver 2001:4860:4860::8888\n" +
+			"\n" +
+			"options ndots:5\n" +
+			"options timeout:2 rotate ; inline comment\n" +
+			"sortlist 130.155.160.0/255.255.240.0 130.155.0.0\n",
+			ResolvConf{Nameservers: []string{"10.0.0.2", "2001:4860:4860::8888"}, SortList: []string{"130.155.160.0/255.255.240.0", "130.155.0.0"},
+				Options: []string{"ndots:5", "timeout:2", "rotate"}}},
+		{"search after domain wins", "domain example.org\nsearch a.example b.example\n",
+			ResolvConf{Search: []string{"a.example", "b.example"}}},
+		{"domain after search wins", "search a.example b.example\ndomain example.org\n",
+			ResolvConf{Domain: "example.org"}},
+		{"lines without a value", "nameserver\nsearch\noptions\n", ResolvConf{}},
+		{"empty", "", ResolvConf{}},
+	}
+	for _, tt := range tests {
+		got, err := ReadResolvConf(writeProcFixture(t, "resolv.conf", tt.content))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadNSSwitch(t *testing.T) {
+	got, err := ReadNSSwitch(writeProcFixture(t, "nsswitch.conf", ""+
+		"# /etc/nsswitch.conf\n"+
+		"\n"+
+		"passwd:         files systemd\n"+
+		"group:          files systemd # with systemd-userdbd\n"+
+		"hosts:          files mdns4_minimal [NOTFOUND=return] dns myhostname\n"+
+		"networks:       files\n"+
+		"netgroup:\n"+
+		"malformed line\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := NSSwitch{
+		"passwd":   {"files", "systemd"},
+		"group":    {"files", "systemd"},
+		"hosts":    {"files", "mdns4_minimal", "[NOTFOUND=return]", "dns", "myhostname"},
+		"networks": {"files"},
+		"netgroup": {},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("nsswitch = %v, want %v", got, want)
+	}
+}
+
+func TestReadHosts(t *testing.T) {
+	got, err := ReadHosts(writeProcFixture(t, "hosts", ""+
+		"# Static table lookup for hostnames.\n"+
+		"127.0.0.1\tlocalhost\n"+
+		"127.0.1.1   web01.example.com web01   # this host\n"+
+		"\n"+
+		"::1     localhost ip6-localhost ip6-loopback\n"+
+		"fe80::1%eth0 router\n"+
+		"10.0.0.9\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []HostEntry{
+		{IP: "127.0.0.1", Hostnames: []string{"localhost"}},
+		{IP: "127.0.1.1", Hostnames: []string{"web01.example.com", "web01"}, Comment: "this host"},
+		{IP: "::1", Hostnames: []string{"localhost", "ip6-localhost", "ip6-loopback"}},
+		{IP: "fe80::1%eth0", Hostnames: []string{"router"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %+v, want %+v", got, want)
+	}
+}
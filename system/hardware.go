@@ -1,69 +1,182 @@
 package system
 
 import (
+	"bufio"
 	"fmt"
-
-	"github.com/GomdimApps/lcme/system/utils"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 )
 
+// dmiSysfsPath is where the kernel exposes the DMI (SMBIOS) tables of the firmware.
+const dmiSysfsPath = "/sys/class/dmi/id"
+
 // HardwareInfo is a structure that contains information about the server's hardware,
-// such as kernel version, processor name, uptime and swap information.
+// such as kernel version, processor name, uptime, swap information and the DMI data of the machine.
 // This information can be collected by the GetHardwareInfo function and is part of the information returned by GetInfoServer.
 type HardwareInfo struct {
 	KernelVersion string
 	ProcessorName string
-	Uptime        int
-	SwapTotal     int
-	SwapFree      int
+	Uptime        int // Minutes.
+	SwapTotal     int // Megabytes.
+	SwapFree      int // Megabytes.
+	DMI           DMIInfo
+}
+
+// DMIInfo identifies the machine from the DMI tables of its firmware. Fields are empty when the
+// firmware does not provide them, as on most ARM boards, or when they are only readable by root,
+// as the serial numbers are.
+type DMIInfo struct {
+	SystemVendor   string
+	ProductName    string
+	ProductVersion string
+	ProductSerial  string
+	ProductUUID    string
+	BIOSVendor     string
+	BIOSVersion    string
+	BIOSDate       string
+	BoardVendor    string
+	BoardName      string
+	BoardVersion   string
+	BoardSerial    string
+	ChassisVendor  string
+	ChassisType    string
 }
 
 // GetHardwareInfo is a function that retrieves information about the system's hardware.
-// It uses operating system commands to extract data about the kernel version,
-// the processor name, the server uptime and the swap area (total and free).
-// This data is then returned in the HardwareInfo structure.
+// It reads the kernel version from /proc/version, the processor name from /proc/cpuinfo,
+// the server uptime from /proc/uptime and the swap area (total and free) from /proc/meminfo,
+// and the DMI data from /sys/class/dmi/id. This data is then returned in the HardwareInfo structure.
 // The function is called within GetInfoServer to collect information about the server's hardware.
-func GetHardwareInfo() HardwareInfo {
-	// Kernel version
-	kernelVersion, err := utils.Cexec("cat /proc/version | awk '{print $3}'")
+// On error, the fields that could be read are still returned.
+func GetHardwareInfo() (HardwareInfo, error) {
+	var info HardwareInfo
+	var firstErr error
+	setErr := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	kernelVersion, err := readKernelVersion()
 	if err != nil {
-		fmt.Printf("Error obtaining kernel version: %v\n", err)
+		setErr(err)
 	}
+	info.KernelVersion = kernelVersion
 
-	// CPU Name
-	nameCpu, err := utils.Cexec("cat /proc/cpuinfo | grep 'model name' | uniq | awk -F ': ' '{print $2}'")
+	processorName, err := readProcessorName()
 	if err != nil {
-		fmt.Printf("Error obtaining CPU core count: %v\n", err)
+		setErr(err)
 	}
+	info.ProcessorName = processorName
 
-	// Server uptime
-	uptimeStr, err := utils.Cexec("awk '{print int($1/60)}' /proc/uptime")
+	uptime, err := readUptimeSeconds()
 	if err != nil {
-		fmt.Printf("Error obtaining server uptime: %v\n", err)
+		setErr(err)
 	}
+	info.Uptime = int(uptime / 60)
 
-	// Swap Total
-	swapTotalStr, err := utils.Cexec("cat /proc/meminfo | grep 'SwapTotal' | uniq | awk '{print $2}'")
+	memory, err := GetMemoryInfo()
 	if err != nil {
-		fmt.Printf("Error obtaining server Swap Total: %v\n", err)
+		setErr(err)
+	}
+	info.setSwap(memory)
+
+	info.DMI = GetDMIInfo()
+	return info, firstErr
+}
+
+// setSwap fills the swap fields, in megabytes, from the byte counts of memory.
+func (h *HardwareInfo) setSwap(memory MemoryInfo) {
+	h.SwapTotal = int(memory.SwapTotal / 1024 / 1024)
+	h.SwapFree = int(memory.SwapFree / 1024 / 1024)
+}
+
+// GetDMIInfo reads the DMI data of the machine from /sys/class/dmi/id.
+// Attributes that are missing or unreadable are left empty.
+func GetDMIInfo() DMIInfo {
+	return readDMIInfo(dmiSysfsPath)
+}
+
+// readDMIInfo reads the DMI attributes from dir, a directory laid out like /sys/class/dmi/id.
+func readDMIInfo(dir string) DMIInfo {
+	read := func(name string) string {
+		value, _ := readSysfsString(filepath.Join(dir, name))
+		return value
+	}
+	return DMIInfo{
+		SystemVendor:   read("sys_vendor"),
+		ProductName:    read("product_name"),
+		ProductVersion: read("product_version"),
+		ProductSerial:  read("product_serial"),
+		ProductUUID:    read("product_uuid"),
+		BIOSVendor:     read("bios_vendor"),
+		BIOSVersion:    read("bios_version"),
+		BIOSDate:       read("bios_date"),
+		BoardVendor:    read("board_vendor"),
+		BoardName:      read("board_name"),
+		BoardVersion:   read("board_version"),
+		BoardSerial:    read("board_serial"),
+		ChassisVendor:  read("chassis_vendor"),
+		ChassisType:    read("chassis_type"),
 	}
+}
 
-	// Swap Free
-	swapFreeStr, err := utils.Cexec("cat /proc/meminfo | grep 'SwapFree' | uniq | awk '{print $2}'")
+// readKernelVersion returns the release from /proc/version, which starts with
+// "Linux version 6.8.0-45-generic (buildd@lcy02-amd64-017) ...".
+func readKernelVersion() (string, error) {
+	data, err := os.ReadFile("/proc/version")
 	if err != nil {
-		fmt.Printf("Error obtaining server Swap free: %v\n", err)
+		return "", err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return "", fmt.Errorf("invalid /proc/version: %q", data)
 	}
+	return fields[2], nil
+}
 
-	// Convert String
-	ints, err := utils.PassInts(uptimeStr, swapTotalStr, swapFreeStr)
+// readProcessorName returns the model name of the first processor in /proc/cpuinfo,
+// or the "Processor" line that older ARM kernels have instead.
+func readProcessorName() (string, error) {
+	file, err := os.Open("/proc/cpuinfo")
 	if err != nil {
-		fmt.Printf("Error converting values to integers: %v\n", err)
+		return "", err
 	}
+	defer file.Close()
 
-	return HardwareInfo{
-		KernelVersion: kernelVersion,
-		ProcessorName: nameCpu,
-		Uptime:        ints[0],
-		SwapTotal:     ints[1] / 1024,
-		SwapFree:      ints[2] / 1024,
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // The flags line is long.
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "model name", "Processor":
+			return strings.TrimSpace(value), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("error reading /proc/cpuinfo: %v", err)
+	}
+	return "", nil
+}
+
+// readUptimeSeconds returns the first value of /proc/uptime, the seconds since boot.
+func readUptimeSeconds() (float64, error) {
+	data, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("invalid /proc/uptime: %q", data)
+	}
+	uptime, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid /proc/uptime: %v", err)
 	}
+	return uptime, nil
 }
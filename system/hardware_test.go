@@ -0,0 +1,78 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadDMIInfo(t *testing.T) {
+	dir := t.TempDir()
+	writeSysfsFixture(t, dir, map[string]string{
+		"sys_vendor":      "LENOVO",
+		"product_name":    "20XW0055US",
+		"product_version": "ThinkPad X1 Carbon Gen 9",
+		"product_uuid":    "4c4c4544-0042-3510-8052-b4c04f4e3732",
+		"bios_vendor":     "LENOVO",
+		"bios_version":    "N32ET86W (1.62 )",
+		"bios_date":       "07/04/2023",
+		"board_vendor":    "LENOVO",
+		"board_name":      "20XW0055US",
+		"board_version":   "SDK0J40697 WIN",
+		"chassis_vendor":  "LENOVO",
+		"chassis_type":    "10",
+	})
+	// The serial numbers are only readable by root.
+	writeSysfsFixture(t, dir, map[string]string{"product_serial": "PF3ABCDE", "board_serial": "L1HF1AB0CDE"})
+	for _, name := range []string{"product_serial", "board_serial"} {
+		if err := os.Chmod(filepath.Join(dir, name), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := DMIInfo{
+		SystemVendor:   "LENOVO",
+		ProductName:    "20XW0055US",
+		ProductVersion: "ThinkPad X1 Carbon Gen 9",
+		ProductUUID:    "4c4c4544-0042-3510-8052-b4c04f4e3732",
+		BIOSVendor:     "LENOVO",
+		BIOSVersion:    "N32ET86W (1.62 )",
+		BIOSDate:       "07/04/2023",
+		BoardVendor:    "LENOVO",
+		BoardName:      "20XW0055US",
+		BoardVersion:   "SDK0J40697 WIN",
+		ChassisVendor:  "LENOVO",
+		ChassisType:    "10",
+	}
+	if os.Geteuid() == 0 {
+		want.ProductSerial, want.BoardSerial = "PF3ABCDE", "L1HF1AB0CDE"
+	}
+	if got := readDMIInfo(dir); got != want {
+		t.Errorf("readDMIInfo = %+v, want %+v", got, want)
+	}
+}
+
+func TestReadDMIInfoMissing(t *testing.T) {
+	// ARM boards without SMBIOS have no DMI directory at all.
+	if got := readDMIInfo(filepath.Join(t.TempDir(), "dmi", "id")); got != (DMIInfo{}) {
+		t.Errorf("readDMIInfo of a missing directory = %+v, want empty", got)
+	}
+}
+
+func TestHardwareSwapFromMeminfo(t *testing.T) {
+	path := writeProcFixture(t, "meminfo", ""+
+		"MemTotal:       16318412 kB\n"+
+		"MemFree:         1234560 kB\n"+
+		"SwapTotal:       4194300 kB\n"+
+		"SwapFree:        3670012 kB\n")
+	memory, err := readMemoryInfo(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info HardwareInfo
+	info.setSwap(memory)
+	// 4194300 kB is 4095.99 MB and 3670012 kB is 3583.99 MB: both are rounded down.
+	if info.SwapTotal != 4095 || info.SwapFree != 3583 {
+		t.Errorf("swap = %d MB total, %d MB free; want 4095 and 3583", info.SwapTotal, info.SwapFree)
+	}
+}
@@ -0,0 +1,279 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// HostsFile edits a hosts file such as /etc/hosts. Comments, blank lines and the lines that are
+// not changed are written back exactly as they were read. Save replaces the file atomically
+// when it can and keeps the previous version as a backup. A HostsFile is not safe for concurrent use.
+type HostsFile struct {
+	path  string
+	lines []hostsLine
+}
+
+// hostsLine is a line of a hosts file. entry is nil for comments and blank lines;
+// raw is the original text, cleared when the entry is changed.
+type hostsLine struct {
+	raw   string
+	entry *HostEntry
+}
+
+// OpenHostsFile reads a hosts file for editing.
+func OpenHostsFile(path string) (*HostsFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	h := &HostsFile{path: path}
+	if len(data) == 0 {
+		return h, nil
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		h.lines = append(h.lines, hostsLine{raw: line, entry: parseHostsLine(line)})
+	}
+	return h, nil
+}
+
+// parseHostsLine parses "192.168.0.10  server server.local  # comment", or returns nil
+// for comments, blank lines and lines without a name.
+func parseHostsLine(line string) *HostEntry {
+	text, comment, _ := strings.Cut(line, "#")
+	fields := strings.Fields(text)
+	if len(fields) < 2 {
+		return nil
+	}
+	return &HostEntry{IP: fields[0], Hostnames: fields[1:], Comment: strings.TrimSpace(comment)}
+}
+
+// Entries returns the entries of the file, in order.
+func (h *HostsFile) Entries() []HostEntry {
+	var entries []HostEntry
+	for _, line := range h.lines {
+		if line.entry != nil {
+			entry := *line.entry
+			entry.Hostnames = append([]string(nil), entry.Hostnames...)
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Lookup returns the addresses of a hostname, in file order. Hostnames are compared without
+// regard to case, as in every method of HostsFile.
+func (h *HostsFile) Lookup(hostname string) []string {
+	var ips []string
+	for _, line := range h.lines {
+		if line.entry != nil && hasHostname(line.entry.Hostnames, hostname) {
+			ips = append(ips, line.entry.IP)
+		}
+	}
+	return ips
+}
+
+// Add maps hostnames to ip. The names are appended to the first entry of ip, or to a new entry
+// at the end of the file; names already present on that entry are skipped. Addresses are
+// compared by value, so "::1" and "0:0::1" are the same entry.
+func (h *HostsFile) Add(ip string, hostnames ...string) error {
+	if err := validateHostEntry(ip, hostnames); err != nil {
+		return err
+	}
+	for i := range h.lines {
+		entry := h.lines[i].entry
+		if entry == nil || !sameIP(entry.IP, ip) {
+			continue
+		}
+		for _, hostname := range hostnames {
+			if !hasHostname(entry.Hostnames, hostname) {
+				entry.Hostnames = append(entry.Hostnames, hostname)
+				h.lines[i].raw = ""
+			}
+		}
+		return nil
+	}
+	entry := &HostEntry{IP: ip, Hostnames: append([]string(nil), hostnames...)}
+	h.lines = append(h.lines, hostsLine{entry: entry})
+	return nil
+}
+
+// Remove removes hostname from every entry. Entries left without names are removed.
+// It reports whether the name was found.
+func (h *HostsFile) Remove(hostname string) bool {
+	found := false
+	lines := h.lines[:0]
+	for _, line := range h.lines {
+		if line.entry != nil && hasHostname(line.entry.Hostnames, hostname) {
+			found = true
+			var kept []string
+			for _, name := range line.entry.Hostnames {
+				if !strings.EqualFold(name, hostname) {
+					kept = append(kept, name)
+				}
+			}
+			if len(kept) == 0 {
+				continue
+			}
+			line.entry.Hostnames = kept
+			line.raw = ""
+		}
+		lines = append(lines, line)
+	}
+	h.lines = lines
+	return found
+}
+
+// RemoveIP removes every entry of ip, compared by value, and reports whether one was found.
+func (h *HostsFile) RemoveIP(ip string) bool {
+	found := false
+	lines := h.lines[:0]
+	for _, line := range h.lines {
+		if line.entry != nil && sameIP(line.entry.IP, ip) {
+			found = true
+			continue
+		}
+		lines = append(lines, line)
+	}
+	h.lines = lines
+	return found
+}
+
+// Update maps hostname to ip only, removing it from the entries of other addresses.
+func (h *HostsFile) Update(hostname, ip string) error {
+	if err := validateHostEntry(ip, []string{hostname}); err != nil {
+		return err
+	}
+	h.Remove(hostname)
+	return h.Add(ip, hostname)
+}
+
+// Save writes the file atomically: the content goes to a temporary file in the same directory,
+// which is synced and renamed over the original. The previous content is kept in path + ".bak".
+// Symlinks are followed, so the file they point to is replaced rather than the link. When the
+// file cannot be replaced, as with the /etc/hosts that Docker and Kubernetes bind mount into
+// containers, it is truncated and rewritten in place instead.
+func (h *HostsFile) Save() error {
+	var b strings.Builder
+	for _, line := range h.lines {
+		if line.entry != nil && line.raw == "" {
+			b.WriteString(formatHostEntry(*line.entry))
+		} else {
+			b.WriteString(line.raw)
+		}
+		b.WriteByte('\n')
+	}
+
+	path := h.path
+	if resolved, err := filepath.EvalSymlinks(h.path); err == nil {
+		path = resolved
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+		previous, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %v", path, err)
+		}
+		if err := os.WriteFile(h.path+".bak", previous, mode); err != nil {
+			return fmt.Errorf("error writing backup: %v", err)
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("error creating temporary file: %v", err)
+	}
+	defer os.Remove(tmp.Name()) // Fails harmlessly once the file has been renamed.
+
+	if _, err := tmp.WriteString(b.String()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing temporary file: %v", err)
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error setting permissions: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error syncing temporary file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error closing temporary file: %v", err)
+	}
+	err = os.Rename(tmp.Name(), path)
+	if errors.Is(err, syscall.EBUSY) || errors.Is(err, syscall.EXDEV) {
+		// A mount point cannot be renamed over.
+		err = writeInPlace(path, b.String())
+	}
+	if err != nil {
+		return fmt.Errorf("error replacing %s: %v", path, err)
+	}
+	return nil
+}
+
+// writeInPlace truncates the file at path and writes content to it, keeping its inode.
+func writeInPlace(path, content string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// hasHostname reports whether names contains hostname, ignoring case.
+func hasHostname(names []string, hostname string) bool {
+	for _, name := range names {
+		if strings.EqualFold(name, hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameIP reports whether two addresses are equal, comparing them as text when
+// one of them does not parse, e.g. a link-local address with a zone.
+func sameIP(a, b string) bool {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	if ipA == nil || ipB == nil {
+		return a == b
+	}
+	return ipA.Equal(ipB)
+}
+
+// formatHostEntry renders an entry as "ip<TAB>names # comment".
+func formatHostEntry(entry HostEntry) string {
+	line := entry.IP + "\t" + strings.Join(entry.Hostnames, " ")
+	if entry.Comment != "" {
+		line += " # " + entry.Comment
+	}
+	return line
+}
+
+// validateHostEntry checks that ip is an IP address and that the hostnames are valid names.
+func validateHostEntry(ip string, hostnames []string) error {
+	if net.ParseIP(ip) == nil {
+		return fmt.Errorf("invalid IP address %q", ip)
+	}
+	if len(hostnames) == 0 {
+		return fmt.Errorf("no hostname for %s", ip)
+	}
+	for _, hostname := range hostnames {
+		if hostname == "" || len(hostname) > 253 || strings.ContainsAny(hostname, " \t#") {
+			return fmt.Errorf("invalid hostname %q", hostname)
+		}
+	}
+	return nil
+}
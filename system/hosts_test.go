@@ -0,0 +1,157 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+const testHosts = "127.0.0.1\tlocalhost\n::1\tip6-localhost\n"
+
+func TestHostsFileAddComparesAddresses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte(testHosts), 0644); err != nil {
+		t.Fatal(err)
+	}
+	h, err := OpenHostsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Add("0:0::1", "ip6-loopback"); err != nil {
+		t.Fatal(err)
+	}
+	want := []HostEntry{
+		{IP: "127.0.0.1", Hostnames: []string{"localhost"}},
+		{IP: "::1", Hostnames: []string{"ip6-localhost", "ip6-loopback"}},
+	}
+	if got := h.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %+v, want %+v", got, want)
+	}
+	if !h.RemoveIP("0000::1") || len(h.Entries()) != 1 {
+		t.Errorf("RemoveIP did not remove ::1: %+v", h.Entries())
+	}
+}
+
+func TestHostsFileSaveFollowsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "hosts.real")
+	link := filepath.Join(dir, "hosts")
+	if err := os.WriteFile(target, []byte(testHosts), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := OpenHostsFile(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Add("10.0.0.5", "db"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("the symlink was replaced: %v, %v", info.Mode(), err)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := testHosts + "10.0.0.5\tdb\n"; string(data) != want {
+		t.Errorf("target = %q, want %q", data, want)
+	}
+}
+
+func TestHostsFileSaveBindMount(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source")
+	path := filepath.Join(dir, "hosts")
+	for _, name := range []string{source, path} {
+		if err := os.WriteFile(name, []byte(testHosts), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := unix.Mount(source, path, "", unix.MS_BIND, ""); err != nil {
+		t.Skip("cannot bind mount:", err)
+	}
+	defer unix.Unmount(path, 0)
+
+	h, err := OpenHostsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Add("10.0.0.5", "db"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := testHosts + "10.0.0.5\tdb\n"; string(data) != want {
+		t.Errorf("mounted file = %q, want %q", data, want)
+	}
+}
+
+func TestHostsFileHostnamesIgnoreCase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte("10.0.0.5\tDB.example.com db\n10.0.0.6\tweb\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	h, err := OpenHostsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := h.Lookup("db.EXAMPLE.com"); !reflect.DeepEqual(got, []string{"10.0.0.5"}) {
+		t.Errorf("Lookup = %v, want [10.0.0.5]", got)
+	}
+	if err := h.Add("10.0.0.6", "WEB"); err != nil {
+		t.Fatal(err)
+	}
+	if !h.Remove("Db") {
+		t.Error("Remove did not find Db")
+	}
+	want := []HostEntry{
+		{IP: "10.0.0.5", Hostnames: []string{"DB.example.com"}},
+		{IP: "10.0.0.6", Hostnames: []string{"web"}},
+	}
+	if got := h.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %+v, want %+v", got, want)
+	}
+}
+
+func TestHostsFileEmpty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	h, err := OpenHostsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(path); err != nil || len(data) != 0 {
+		t.Errorf("saved empty file = %q, %v; want no content", data, err)
+	}
+
+	if err := h.Add("10.0.0.5", "db"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "10.0.0.5\tdb\n" {
+		t.Errorf("file = %q, %v; want %q", data, err, "10.0.0.5\tdb\n")
+	}
+}
@@ -0,0 +1,136 @@
+package system
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// InfoSection selects a part of ServerInfo. Sections can be combined with '|'.
+type InfoSection uint
+
+const (
+	SectionDistribution InfoSection = 1 << iota
+	SectionRAM
+	SectionDisk
+	SectionCPU
+	SectionNetwork
+	SectionHardware
+
+	SectionAll = SectionDistribution | SectionRAM | SectionDisk | SectionCPU | SectionNetwork | SectionHardware
+)
+
+const defaultSampleWindow = time.Second
+
+// InfoOptions selects what GetServerInfo collects and how long the sampled values are measured.
+type InfoOptions struct {
+	Sections      InfoSection   // Sections to collect. Zero collects all of them.
+	CPUWindow     time.Duration // Time between the two CPU samples used to compute the usage. Defaults to 1s.
+	NetworkWindow time.Duration // Time between the two network samples used to compute the rates. Defaults to 1s.
+	DiskPath      string        // Path whose file system is reported in Disk. Defaults to "/".
+}
+
+// GetServerInfo collects the selected sections of ServerInfo. The collectors run concurrently,
+// so the call takes about as long as the longest sampling window instead of their sum.
+// If ctx is done first, the sections collected so far are returned with the context error.
+// Sections that fail are left empty and their first error is returned.
+func GetServerInfo(ctx context.Context, opts InfoOptions) (ServerInfo, error) {
+	if opts.Sections == 0 {
+		opts.Sections = SectionAll
+	}
+	if opts.CPUWindow <= 0 {
+		opts.CPUWindow = defaultSampleWindow
+	}
+	if opts.NetworkWindow <= 0 {
+		opts.NetworkWindow = defaultSampleWindow
+	}
+	if opts.DiskPath == "" {
+		opts.DiskPath = "/"
+	}
+
+	var (
+		mu       sync.Mutex
+		info     ServerInfo
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	// collect runs a collector in its own goroutine and stores its result under mu, so that
+	// a collector finishing after the deadline cannot race with the returned copy.
+	collect := func(section InfoSection, fn func() error) {
+		if opts.Sections&section == 0 {
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	collect(SectionDistribution, func() error {
+		distro, err := GetDistroInfo()
+		mu.Lock()
+		info.Distribution = distro
+		mu.Unlock()
+		return err
+	})
+	collect(SectionRAM, func() error {
+		ram := GetRAMInfo()
+		mu.Lock()
+		info.RAM = ram
+		mu.Unlock()
+		return nil
+	})
+	collect(SectionDisk, func() error {
+		disk := GetDiskInfo(opts.DiskPath)
+		mu.Lock()
+		info.Disk = disk
+		mu.Unlock()
+		return nil
+	})
+	collect(SectionCPU, func() error {
+		cpu, err := GetCPUInfoWith(ctx, opts.CPUWindow)
+		mu.Lock()
+		info.CPU = cpu
+		mu.Unlock()
+		return err
+	})
+	collect(SectionNetwork, func() error {
+		network, err := GetNetworkInfoWith(ctx, opts.NetworkWindow)
+		mu.Lock()
+		info.Network = network
+		mu.Unlock()
+		return err
+	})
+	collect(SectionHardware, func() error {
+		hardware, err := GetHardwareInfo()
+		mu.Lock()
+		info.Hardware = hardware
+		mu.Unlock()
+		return err
+	})
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return info, err
+	}
+	return info, firstErr
+}
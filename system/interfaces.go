@@ -0,0 +1,252 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+const netSysfsPath = "/sys/class/net"
+
+// InterfaceType classifies a network interface.
+type InterfaceType string
+
+const (
+	InterfacePhysical InterfaceType = "physical"
+	InterfaceLoopback InterfaceType = "loopback"
+	InterfaceBridge   InterfaceType = "bridge"
+	InterfaceBond     InterfaceType = "bond"
+	InterfaceVLAN     InterfaceType = "vlan"
+	InterfaceVeth     InterfaceType = "veth"
+	InterfaceTun      InterfaceType = "tun"     // TUN and TAP devices.
+	InterfaceVirtual  InterfaceType = "virtual" // Other software devices, e.g. macvlan, vxlan, gre or dummy.
+)
+
+// InterfaceAddress is an IP address assigned to an interface.
+type InterfaceAddress struct {
+	IP           net.IP
+	PrefixLength int
+}
+
+// String returns the address in CIDR notation, e.g. "192.168.0.10/24".
+func (a InterfaceAddress) String() string {
+	return a.IP.String() + "/" + strconv.Itoa(a.PrefixLength)
+}
+
+// InterfaceCounters holds the cumulative counters of one direction of an interface, from /proc/net/dev.
+// Frame and Multicast are only reported for received traffic, Collisions and Carrier for transmitted traffic.
+type InterfaceCounters struct {
+	Bytes      uint64
+	Packets    uint64
+	Errors     uint64
+	Drops      uint64
+	FIFO       uint64
+	Compressed uint64
+	Frame      uint64
+	Multicast  uint64
+	Collisions uint64
+	Carrier    uint64
+}
+
+// InterfaceInfo describes a network interface with its link details, addresses and counters.
+type InterfaceInfo struct {
+	Name      string
+	Index     int
+	Type      InterfaceType
+	MAC       string
+	MTU       int
+	OperState string // "up", "down", "dormant", "lowerlayerdown" or "unknown".
+	Carrier   bool   // Whether the link is detected.
+	Speed     int    // Link speed in Mbit/s, or zero when unknown or down.
+	Duplex    string // "full", "half" or "unknown".
+	Master    string // Bridge or bond the interface belongs to.
+	Flags     net.Flags
+	Addresses []InterfaceAddress
+	RX        InterfaceCounters
+	TX        InterfaceCounters
+}
+
+// GetInterfaces returns every network interface with its link details from /sys/class/net,
+// its addresses and its counters from /proc/net/dev.
+func GetInterfaces() ([]InterfaceInfo, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("error listing network interfaces: %v", err)
+	}
+	counters, err := readNetDev()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]InterfaceInfo, 0, len(ifaces))
+	for _, iface := range ifaces {
+		infos = append(infos, buildInterfaceInfo(iface, counters[iface.Name]))
+	}
+	return infos, nil
+}
+
+// GetInterface returns the details of one network interface.
+func GetInterface(name string) (InterfaceInfo, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return InterfaceInfo{}, err
+	}
+	counters, err := readNetDev()
+	if err != nil {
+		return InterfaceInfo{}, err
+	}
+	return buildInterfaceInfo(*iface, counters[name]), nil
+}
+
+// buildInterfaceInfo combines the details reported by the net package, sysfs and /proc/net/dev.
+func buildInterfaceInfo(iface net.Interface, counters [2]InterfaceCounters) InterfaceInfo {
+	dir := filepath.Join(netSysfsPath, iface.Name)
+	info := InterfaceInfo{
+		Name:    iface.Name,
+		Index:   iface.Index,
+		Type:    interfaceType(dir, iface),
+		MAC:     iface.HardwareAddr.String(),
+		MTU:     iface.MTU,
+		Carrier: readSysfsBool(filepath.Join(dir, "carrier")),
+		Flags:   iface.Flags,
+		RX:      counters[0],
+		TX:      counters[1],
+	}
+	info.OperState, _ = readSysfsString(filepath.Join(dir, "operstate"))
+	info.Duplex, _ = readSysfsString(filepath.Join(dir, "duplex"))
+	// Reading speed fails with EINVAL while the link is down; virtual interfaces report -1.
+	if speed, err := readSysfsInt(filepath.Join(dir, "speed")); err == nil && speed > 0 {
+		info.Speed = speed
+	}
+	if master, err := os.Readlink(filepath.Join(dir, "master")); err == nil {
+		info.Master = filepath.Base(master)
+	}
+
+	if addrs, err := iface.Addrs(); err == nil {
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok {
+				prefix, _ := ipNet.Mask.Size()
+				info.Addresses = append(info.Addresses, InterfaceAddress{IP: ipNet.IP, PrefixLength: prefix})
+			}
+		}
+	}
+	return info
+}
+
+// interfaceType classifies an interface from its sysfs directory. Interfaces backed by a device,
+// such as PCI or USB NICs, are physical; the other software interfaces are told apart by the
+// directories and attributes their drivers add.
+func interfaceType(dir string, iface net.Interface) InterfaceType {
+	devType := readUeventValue(filepath.Join(dir, "uevent"), "DEVTYPE")
+	switch {
+	case iface.Flags&net.FlagLoopback != 0:
+		return InterfaceLoopback
+	case fileExists(filepath.Join(dir, "bridge")):
+		return InterfaceBridge
+	case fileExists(filepath.Join(dir, "bonding")):
+		return InterfaceBond
+	case devType == "vlan":
+		return InterfaceVLAN
+	case fileExists(filepath.Join(dir, "tun_flags")):
+		return InterfaceTun
+	case fileExists(filepath.Join(dir, "device")):
+		return InterfacePhysical
+	}
+	// A veth has neither a DEVTYPE nor a marker file, and its iflink pointing to the peer is shared
+	// with macvlan, ipvlan and tunnel devices, so only its driver tells it apart.
+	return driverInterfaceType(interfaceDriver(iface.Name))
+}
+
+// driverInterfaces are the interface types told apart by the name of their driver.
+var driverInterfaces = map[string]InterfaceType{
+	"veth":    InterfaceVeth,
+	"bridge":  InterfaceBridge,
+	"bonding": InterfaceBond,
+	"8021q":   InterfaceVLAN,
+	"tun":     InterfaceTun,
+}
+
+// driverInterfaceType classifies a software interface by its driver, as returned by interfaceDriver.
+// Other drivers, and an unknown driver, are virtual.
+func driverInterfaceType(driver string) InterfaceType {
+	if t, ok := driverInterfaces[driver]; ok {
+		return t
+	}
+	return InterfaceVirtual
+}
+
+// interfaceDriver returns the driver of an interface, e.g. "veth", "macvlan" or "e1000e", as reported
+// by the SIOCETHTOOL ioctl like ethtool -i, or "" when it is not available.
+func interfaceDriver(name string) string {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return ""
+	}
+	defer unix.Close(fd)
+	info, err := unix.IoctlGetEthtoolDrvinfo(fd, name)
+	if err != nil {
+		return ""
+	}
+	return unix.ByteSliceToString(info.Driver[:])
+}
+
+// readUeventValue returns the value of a KEY=value line of a uevent file.
+func readUeventValue(path, key string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if name, value, ok := strings.Cut(line, "="); ok && name == key {
+			return value
+		}
+	}
+	return ""
+}
+
+// readNetDev reads the receive and transmit counters of every interface from /proc/net/dev.
+func readNetDev() (map[string][2]InterfaceCounters, error) {
+	file, err := os.Open("/proc/net/dev")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	counters := make(map[string][2]InterfaceCounters)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// The two header lines have no ':'; large counters can touch it, as in "eth0:123 ...".
+		name, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) < 16 {
+			continue
+		}
+		var values [16]uint64
+		for i := range values {
+			values[i], _ = strconv.ParseUint(fields[i], 10, 64)
+		}
+		counters[strings.TrimSpace(name)] = [2]InterfaceCounters{
+			{
+				Bytes: values[0], Packets: values[1], Errors: values[2], Drops: values[3],
+				FIFO: values[4], Frame: values[5], Compressed: values[6], Multicast: values[7],
+			},
+			{
+				Bytes: values[8], Packets: values[9], Errors: values[10], Drops: values[11],
+				FIFO: values[12], Collisions: values[13], Carrier: values[14], Compressed: values[15],
+			},
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading /proc/net/dev: %v", err)
+	}
+	return counters, nil
+}
@@ -0,0 +1,40 @@
+package system
+
+import "testing"
+
+func TestInterfaceTypeLoopback(t *testing.T) {
+	info, err := GetInterface("lo")
+	if err != nil {
+		t.Skip("no loopback interface:", err)
+	}
+	if info.Type != InterfaceLoopback {
+		t.Errorf("lo type = %s, want %s", info.Type, InterfaceLoopback)
+	}
+}
+
+func TestDriverInterfaceType(t *testing.T) {
+	tests := []struct {
+		driver string
+		want   InterfaceType
+	}{
+		{"veth", InterfaceVeth},
+		{"bridge", InterfaceBridge},
+		{"bonding", InterfaceBond},
+		{"8021q", InterfaceVLAN},
+		{"tun", InterfaceTun},
+		{"macvlan", InterfaceVirtual},
+		{"virtio_net", InterfaceVirtual},
+		{"", InterfaceVirtual},
+	}
+	for _, tt := range tests {
+		if got := driverInterfaceType(tt.driver); got != tt.want {
+			t.Errorf("driverInterfaceType(%q) = %s, want %s", tt.driver, got, tt.want)
+		}
+	}
+}
+
+func TestInterfaceDriverMissingInterface(t *testing.T) {
+	if driver := interfaceDriver("no-such-interface"); driver != "" {
+		t.Errorf("driver of a missing interface = %q", driver)
+	}
+}
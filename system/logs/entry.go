@@ -0,0 +1,72 @@
+package logs
+
+import (
+	"log/slog"
+	"time"
+)
+
+// TimeFormat is the layout used for timestamps in text log lines.
+const TimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// Field is a key/value pair attached to a log entry.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Entry is a single log record, as passed from a Logger to its Sink.
+type Entry struct {
+	Time    time.Time // Zero when unknown, as for a slog.Record without a time; formats leave it out.
+	Level   Level
+	Message string
+	Fields  []Field
+}
+
+// fieldsFromArgs converts the variadic arguments of the Logger methods into fields.
+// Arguments can be Field or slog.Attr values, or alternating key/value pairs.
+// A value without a key is stored under the "!BADKEY" key, as slog does.
+func fieldsFromArgs(args []any) []Field {
+	if len(args) == 0 {
+		return nil
+	}
+	fields := make([]Field, 0, len(args)/2+1)
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i].(type) {
+		case Field:
+			fields = append(fields, arg)
+		case slog.Attr:
+			fields = appendAttr(fields, "", arg)
+		case string:
+			if i+1 >= len(args) {
+				fields = append(fields, Field{Key: "!BADKEY", Value: arg})
+				continue
+			}
+			fields = append(fields, Field{Key: arg, Value: args[i+1]})
+			i++
+		default:
+			fields = append(fields, Field{Key: "!BADKEY", Value: arg})
+		}
+	}
+	return fields
+}
+
+// appendAttr flattens a slog.Attr into fields, joining group names with dots.
+func appendAttr(fields []Field, prefix string, attr slog.Attr) []Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+	key := attr.Key
+	if prefix != "" && key != "" {
+		key = prefix + "." + key
+	} else if prefix != "" {
+		key = prefix
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		for _, member := range attr.Value.Group() {
+			fields = appendAttr(fields, key, member)
+		}
+		return fields
+	}
+	return append(fields, Field{Key: key, Value: attr.Value.Any()})
+}
@@ -0,0 +1,485 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrExtension is returned when a log file path does not end in .log.
+	ErrExtension = errors.New("the file must have a .log extension")
+	// ErrClosed is returned when writing to a File that has been closed.
+	ErrClosed = errors.New("log file is closed")
+	// ErrOptionsConflict is returned by OpenSharedFile when the file is already open with other options.
+	ErrOptionsConflict = errors.New("log file is already open with other options")
+)
+
+// SyncPolicy selects when a File calls fsync on the log file.
+type SyncPolicy int
+
+const (
+	SyncNever      SyncPolicy = iota // Leave it to the operating system.
+	SyncOnFlush                      // Fsync after every flush of the buffer.
+	SyncEveryWrite                   // Flush and fsync after every write. Slow, but nothing is lost on a crash.
+)
+
+// OverflowPolicy selects what a File or a MultiSink route does when its queue is full.
+type OverflowPolicy int
+
+const (
+	OverflowDefault OverflowPolicy = iota // Block for a File; for a Route, block if the sink is a File and drop otherwise.
+	OverflowBlock                         // Wait until there is room in the queue.
+	OverflowDrop                          // Discard the line and count it in Dropped.
+)
+
+const (
+	defaultBufferSize    = 64 * 1024
+	defaultFlushInterval = time.Second
+)
+
+// FileOptions configures how a File writes to disk.
+type FileOptions struct {
+	Rotation      Rotation       // When to rotate the file. The zero value never rotates.
+	Buffered      bool           // Keep lines in the write buffer until it is full or flushed. By default every write reaches the file.
+	BufferSize    int            // Size of the write buffer in bytes when Buffered is set. Defaults to 64 KB.
+	FlushInterval time.Duration  // How often the background flusher writes the buffer to disk. Defaults to 1s.
+	Sync          SyncPolicy     // When to fsync the file.
+	QueueSize     int            // Number of lines queued for the background writer. Zero writes in the caller's goroutine.
+	Overflow      OverflowPolicy // What to do when the queue is full.
+	Format        Formatter      // Format of the entries written with WriteEntry. Defaults to FormatText.
+}
+
+// queueItem is a line waiting for the background writer, or a flush request when done is set.
+type queueItem struct {
+	data []byte
+	done chan error
+}
+
+// File appends log lines to a .log file through a long-lived handle. With FileOptions.Buffered,
+// lines go through a write buffer that a background goroutine flushes every FlushInterval, and,
+// when QueueSize is set, another goroutine takes the lines from a bounded queue so that callers
+// do not wait for the disk. Writes and rotations are serialized, so a File is safe for concurrent use.
+// Call Close before the program exits so that buffered or queued lines are not lost.
+//
+// Lines that cannot be written are kept in the buffer and written by the next flush, and a file
+// that could not be reopened after a rotation is reopened by the next write.
+type File struct {
+	path string
+	opts FileOptions
+	now  func() time.Time // Clock of the rotation policy; time.Now outside tests.
+
+	mu     sync.Mutex
+	handle *os.File  // Nil after a failed reopen; the next flush opens the file again.
+	buffer []byte    // Lines not yet written to the file.
+	size   int64     // Size of the active file, including buffered bytes.
+	period time.Time // Rotation period of the active file.
+
+	closeMu sync.RWMutex // Held for writing by Close so that no write races with closing the queue.
+	closed  bool
+	queue   chan queueItem
+	dropped atomic.Uint64
+	done    chan struct{}
+	workers sync.WaitGroup
+
+	compressMu   sync.Mutex     // Serializes the background compression of rotated files.
+	compressions sync.WaitGroup // Background compressions still running.
+}
+
+// OpenFile returns a File that writes to the specified path without rotating it.
+// The path must have the .log extension.
+func OpenFile(path string) (*File, error) {
+	return OpenFileWith(path, FileOptions{})
+}
+
+// OpenRotatingFile returns a File that writes to the specified path and rotates it according to rotation.
+// Rotated files are renamed with a timestamp, e.g. app-2024-01-02T15-04-05.000.log,
+// and gzip-compressed in the background.
+func OpenRotatingFile(path string, rotation Rotation) (*File, error) {
+	return OpenFileWith(path, FileOptions{Rotation: rotation})
+}
+
+// withDefaults returns the options with the defaults filled in.
+func (o FileOptions) withDefaults() FileOptions {
+	if o.BufferSize <= 0 {
+		o.BufferSize = defaultBufferSize
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = defaultFlushInterval
+	}
+	if o.Format == nil {
+		o.Format = FormatText
+	}
+	return o
+}
+
+// equal reports whether two sets of options, with their defaults filled in, open the file the same way.
+func (o FileOptions) equal(other FileOptions) bool {
+	return o.Rotation == other.Rotation && o.Buffered == other.Buffered && o.BufferSize == other.BufferSize &&
+		o.FlushInterval == other.FlushInterval && o.Sync == other.Sync && o.QueueSize == other.QueueSize &&
+		o.Overflow == other.Overflow && reflect.ValueOf(o.Format).Pointer() == reflect.ValueOf(other.Format).Pointer()
+}
+
+// OpenFileWith opens the log file at path, creating it if needed, and starts its background goroutines.
+func OpenFileWith(path string, opts FileOptions) (*File, error) {
+	if !strings.HasSuffix(path, ".log") {
+		return nil, ErrExtension
+	}
+	opts = opts.withDefaults()
+
+	f := &File{path: path, opts: opts, now: time.Now, done: make(chan struct{})}
+	if err := f.openHandleLocked(); err != nil {
+		return nil, err
+	}
+	f.period = opts.Rotation.period(f.now())
+	if info, err := f.handle.Stat(); err == nil && info.Size() > 0 {
+		// The file was written before it was opened here; its period is the one of its last change.
+		f.period = opts.Rotation.period(info.ModTime())
+	}
+
+	f.workers.Add(1)
+	go f.flushLoop()
+	if opts.QueueSize > 0 {
+		f.queue = make(chan queueItem, opts.QueueSize)
+		f.workers.Add(1)
+		go f.writeLoop()
+	}
+	registerFile(f)
+	return f, nil
+}
+
+// Path returns the path of the log file.
+func (f *File) Path() string {
+	return f.path
+}
+
+// Dropped returns the number of lines discarded because the queue was full.
+func (f *File) Dropped() uint64 {
+	return f.dropped.Load()
+}
+
+// Write appends p to the log file. With a queue, p is copied and written in the background;
+// otherwise it goes to the write buffer before Write returns.
+func (f *File) Write(p []byte) (int, error) {
+	f.closeMu.RLock()
+	defer f.closeMu.RUnlock()
+	if f.closed {
+		return 0, ErrClosed
+	}
+
+	if f.queue == nil {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if err := f.writeLocked(p); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	item := queueItem{data: append([]byte(nil), p...)}
+	if f.opts.Overflow == OverflowDrop {
+		select {
+		case f.queue <- item:
+		default:
+			f.dropped.Add(1)
+		}
+	} else {
+		f.queue <- item
+	}
+	return len(p), nil
+}
+
+// WriteEntry writes the entry to the log file in the format selected by FileOptions.Format.
+func (f *File) WriteEntry(e Entry) error {
+	_, err := f.Write(f.opts.Format(e))
+	return err
+}
+
+// Flush writes every queued and buffered line to the file, and fsyncs it unless Sync is SyncNever.
+func (f *File) Flush() error {
+	f.closeMu.RLock()
+	defer f.closeMu.RUnlock()
+	if f.closed {
+		return ErrClosed
+	}
+
+	if f.queue != nil {
+		// The request goes through the queue so that it runs after every line written before it.
+		done := make(chan error, 1)
+		f.queue <- queueItem{done: done}
+		return <-done
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.flushLocked(f.opts.Sync != SyncNever)
+}
+
+// Rotate rotates the log file immediately, regardless of the rotation policy.
+func (f *File) Rotate() error {
+	if err := f.Flush(); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	f.period = f.opts.Rotation.period(now)
+	return f.rotateLocked(now)
+}
+
+// Close writes the remaining lines, stops the background goroutines, closes the file
+// and waits for the background compression of rotated files to finish.
+func (f *File) Close() error {
+	f.closeMu.Lock()
+	if f.closed {
+		f.closeMu.Unlock()
+		return nil
+	}
+	f.closed = true
+	if f.queue != nil {
+		close(f.queue)
+	}
+	close(f.done)
+	f.closeMu.Unlock()
+
+	f.workers.Wait()
+	unregisterFile(f)
+
+	f.mu.Lock()
+	err := f.closeHandleLocked()
+	f.mu.Unlock()
+
+	f.compressions.Wait()
+	return err
+}
+
+// writeLoop writes the queued lines until the queue is closed.
+func (f *File) writeLoop() {
+	defer f.workers.Done()
+	for item := range f.queue {
+		f.mu.Lock()
+		var err error
+		if item.done != nil {
+			err = f.flushLocked(f.opts.Sync != SyncNever)
+		} else {
+			err = f.writeLocked(item.data)
+		}
+		f.mu.Unlock()
+
+		if item.done != nil {
+			item.done <- err
+		} else if err != nil {
+			reportError("Error writing to the log file:", err)
+		}
+	}
+}
+
+// flushLoop flushes the buffer every FlushInterval until the file is closed.
+func (f *File) flushLoop() {
+	defer f.workers.Done()
+	ticker := time.NewTicker(f.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.done:
+			return
+		case <-ticker.C:
+			f.mu.Lock()
+			err := f.flushLocked(f.opts.Sync == SyncOnFlush)
+			f.mu.Unlock()
+			if err != nil {
+				reportError("Error flushing the log file:", err)
+			}
+		}
+	}
+}
+
+// writeLocked rotates the file if needed and appends p to the buffer. The caller must hold f.mu.
+func (f *File) writeLocked(p []byte) error {
+	if f.opts.Rotation.enabled() {
+		if err := f.rotateIfNeeded(int64(len(p))); err != nil {
+			return err
+		}
+	}
+	if len(f.buffer) > 0 && len(f.buffer)+len(p) > f.opts.BufferSize {
+		if err := f.flushLocked(false); err != nil {
+			return err
+		}
+	}
+	f.buffer = append(f.buffer, p...)
+	f.size += int64(len(p))
+	switch {
+	case f.opts.Sync == SyncEveryWrite:
+		return f.flushLocked(true)
+	case !f.opts.Buffered:
+		return f.flushLocked(false)
+	}
+	return nil
+}
+
+// flushLocked writes the buffer to the file and optionally fsyncs it. The bytes that could not be
+// written stay in the buffer for the next flush. The caller must hold f.mu.
+func (f *File) flushLocked(sync bool) error {
+	if len(f.buffer) == 0 {
+		return nil
+	}
+	if f.handle == nil {
+		if err := f.openHandleLocked(); err != nil {
+			return err
+		}
+	}
+	n, err := f.handle.Write(f.buffer)
+	f.buffer = f.buffer[:copy(f.buffer, f.buffer[n:])]
+	if err != nil {
+		return err
+	}
+	if sync {
+		return f.handle.Sync()
+	}
+	return nil
+}
+
+// openHandleLocked opens the log file for appending. The caller must hold f.mu.
+func (f *File) openHandleLocked() error {
+	handle, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := handle.Stat()
+	if err != nil {
+		handle.Close()
+		return err
+	}
+	f.handle = handle
+	f.size = info.Size() + int64(len(f.buffer))
+	return nil
+}
+
+// closeHandleLocked flushes the buffer and closes the log file. The caller must hold f.mu.
+func (f *File) closeHandleLocked() error {
+	err := f.flushLocked(f.opts.Sync != SyncNever)
+	if f.handle != nil {
+		if closeErr := f.handle.Close(); err == nil {
+			err = closeErr
+		}
+		f.handle = nil
+	}
+	return err
+}
+
+// rotateIfNeeded rotates the file before a write of n bytes when the policy requires it.
+// The caller must hold f.mu.
+func (f *File) rotateIfNeeded(n int64) error {
+	now := f.now()
+	rotate := f.opts.Rotation.MaxSize > 0 && f.size > 0 && f.size+n > f.opts.Rotation.MaxSize
+	if period := f.opts.Rotation.period(now); !period.Equal(f.period) {
+		f.period = period
+		rotate = true
+	}
+	if !rotate || f.size == 0 {
+		return nil
+	}
+	return f.rotateLocked(now)
+}
+
+var (
+	openFilesMu sync.Mutex
+	openFiles   = make(map[*File]struct{})
+
+	sharedFilesMu sync.Mutex
+	sharedFiles   = make(map[string]*File) // Files opened by OpenSharedFile, by absolute path.
+)
+
+// OpenSharedFile returns the open File shared by every caller of path, opening it with opts when
+// there is none, so that several writers of one file share a single handle, buffer and rotation.
+// If the file is already open with other options, such as another rotation, it returns
+// ErrOptionsConflict. Once the File is closed, directly or by CloseAll, the next call opens the file again.
+func OpenSharedFile(path string, opts FileOptions) (*File, error) {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sharedFilesMu.Lock()
+	defer sharedFilesMu.Unlock()
+	if f, ok := sharedFiles[key]; ok {
+		if !f.opts.equal(opts.withDefaults()) {
+			return nil, fmt.Errorf("%s: %w", path, ErrOptionsConflict)
+		}
+		return f, nil
+	}
+	f, err := OpenFileWith(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	sharedFiles[key] = f
+	return f, nil
+}
+
+// registerFile records an open File so that FlushAll and CloseAll can reach it.
+func registerFile(f *File) {
+	openFilesMu.Lock()
+	openFiles[f] = struct{}{}
+	openFilesMu.Unlock()
+}
+
+// unregisterFile forgets a closed File.
+func unregisterFile(f *File) {
+	openFilesMu.Lock()
+	delete(openFiles, f)
+	openFilesMu.Unlock()
+
+	sharedFilesMu.Lock()
+	for key, shared := range sharedFiles {
+		if shared == f {
+			delete(sharedFiles, key)
+		}
+	}
+	sharedFilesMu.Unlock()
+}
+
+// snapshotFiles returns the Files that are currently open.
+func snapshotFiles() []*File {
+	openFilesMu.Lock()
+	defer openFilesMu.Unlock()
+	files := make([]*File, 0, len(openFiles))
+	for f := range openFiles {
+		files = append(files, f)
+	}
+	return files
+}
+
+// FlushAll flushes every open File. It returns the first error found.
+func FlushAll() error {
+	var first error
+	for _, f := range snapshotFiles() {
+		if err := f.Flush(); err != nil && first == nil && !errors.Is(err, ErrClosed) {
+			first = err
+		}
+	}
+	return first
+}
+
+// CloseAll closes every open File, for use when the program shuts down. It returns the first error found.
+func CloseAll() error {
+	var first error
+	for _, f := range snapshotFiles() {
+		if err := f.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// reportError prints an error from a background goroutine on stderr, since there is no caller to return it to.
+func reportError(msg string, err error) {
+	fmt.Fprintln(os.Stderr, msg, err)
+}
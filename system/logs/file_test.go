@@ -0,0 +1,254 @@
+package logs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileWriteIsUnbufferedByDefault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write([]byte("line\n")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "line\n" {
+		t.Errorf("file content = %q, want %q", data, "line\n")
+	}
+}
+
+func TestFileKeepsLinesWhenRotationFlushFails(t *testing.T) {
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip("/dev/full is not available")
+	}
+	// Writes to /dev/full fail with ENOSPC, like a full disk.
+	path := filepath.Join(t.TempDir(), "full.log")
+	if err := os.Symlink("/dev/full", path); err != nil {
+		t.Fatal(err)
+	}
+	f, err := OpenFileWith(path, FileOptions{Buffered: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write([]byte("line\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Rotate(); err == nil {
+		t.Fatal("Rotate succeeded writing to /dev/full")
+	}
+	if _, err := f.Write([]byte("next\n")); errors.Is(err, ErrClosed) {
+		t.Fatal("Write after a failed rotation returned ErrClosed")
+	}
+
+	f.mu.Lock()
+	buffered := string(f.buffer)
+	f.mu.Unlock()
+	if buffered != "line\nnext\n" {
+		t.Errorf("buffer = %q, want %q", buffered, "line\nnext\n")
+	}
+}
+
+func TestSharedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	a, err := OpenSharedFile(path, FileOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The defaults are the same options.
+	b, err := OpenSharedFile(path, FileOptions{BufferSize: defaultBufferSize, Format: FormatText})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Error("OpenSharedFile opened the same path twice")
+	}
+	for _, opts := range []FileOptions{
+		{Buffered: true},
+		{Rotation: Rotation{MaxSize: 1024}},
+		{Format: FormatJSON},
+	} {
+		if _, err := OpenSharedFile(path, opts); !errors.Is(err, ErrOptionsConflict) {
+			t.Errorf("OpenSharedFile with %+v on an open file: error = %v, want ErrOptionsConflict", opts, err)
+		}
+	}
+
+	a.Close()
+	c, err := OpenSharedFile(path, FileOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if c == a {
+		t.Error("OpenSharedFile returned a closed file")
+	}
+}
+
+// testClock is a settable clock for the rotation policy.
+type testClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *testClock) Set(t time.Time) {
+	c.mu.Lock()
+	c.t = t
+	c.mu.Unlock()
+}
+
+// openFileAt opens a File whose rotation policy reads the time from clock.
+func openFileAt(t *testing.T, path string, opts FileOptions, clock *testClock) *File {
+	t.Helper()
+	f, err := OpenFileWith(path, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.mu.Lock()
+	f.now = clock.Now
+	f.period = f.opts.Rotation.period(clock.Now())
+	f.mu.Unlock()
+	return f
+}
+
+// readBackups returns the names of the rotated files of path and their decompressed content.
+func readBackups(t *testing.T, path string) ([]string, []string) {
+	t.Helper()
+	backups, err := listBackups(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names, contents []string
+	for _, b := range backups {
+		if !b.compressed {
+			t.Errorf("%s was not compressed", b.path)
+		}
+		names = append(names, filepath.Base(b.path))
+		var content strings.Builder
+		if err := scanLogFile(b.path, func(line string) { content.WriteString(line + "\n") }); err != nil {
+			t.Fatal(err)
+		}
+		contents = append(contents, content.String())
+	}
+	return names, contents
+}
+
+func TestFileRotation(t *testing.T) {
+	base := time.Date(2024, 1, 2, 10, 59, 0, 0, time.Local)
+	at := func(d time.Duration) time.Time { return base.Add(d) }
+	backup := func(d time.Duration) string { return filepath.Base(backupName("app.log", at(d))) + ".gz" }
+
+	tests := []struct {
+		name         string
+		rotation     Rotation
+		times        []time.Duration // When each line is written.
+		wantBackups  []string
+		wantContents []string
+		wantActive   string
+	}{
+		{
+			name:         "size",
+			rotation:     Rotation{MaxSize: 12},
+			times:        []time.Duration{0, time.Second, 2 * time.Second, 3 * time.Second, 4 * time.Second},
+			wantBackups:  []string{backup(2 * time.Second), backup(4 * time.Second)},
+			wantContents: []string{"line0\nline1\n", "line2\nline3\n"},
+			wantActive:   "line4\n",
+		},
+		{
+			name:         "hourly",
+			rotation:     Rotation{Interval: RotateHourly},
+			times:        []time.Duration{0, 30 * time.Second, 2 * time.Minute, 62 * time.Minute},
+			wantBackups:  []string{backup(2 * time.Minute), backup(62 * time.Minute)},
+			wantContents: []string{"line0\nline1\n", "line2\n"},
+			wantActive:   "line3\n",
+		},
+		{
+			name:         "daily",
+			rotation:     Rotation{Interval: RotateDaily},
+			times:        []time.Duration{0, 2 * time.Hour, 13 * time.Hour, 14 * time.Hour},
+			wantBackups:  []string{backup(14 * time.Hour)},
+			wantContents: []string{"line0\nline1\nline2\n"},
+			wantActive:   "line3\n",
+		},
+		{
+			name:         "max backups",
+			rotation:     Rotation{MaxSize: 6, MaxBackups: 2},
+			times:        []time.Duration{0, time.Second, 2 * time.Second, 3 * time.Second, 4 * time.Second},
+			wantBackups:  []string{backup(3 * time.Second), backup(4 * time.Second)},
+			wantContents: []string{"line2\n", "line3\n"},
+			wantActive:   "line4\n",
+		},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "app.log")
+		clock := &testClock{t: base}
+		f := openFileAt(t, path, FileOptions{Rotation: tt.rotation}, clock)
+
+		for i, d := range tt.times {
+			clock.Set(at(d))
+			if _, err := f.Write([]byte("line" + strconv.Itoa(i) + "\n")); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		names, contents := readBackups(t, path)
+		if !reflect.DeepEqual(names, tt.wantBackups) {
+			t.Errorf("%s: backups = %q, want %q", tt.name, names, tt.wantBackups)
+		} else if !reflect.DeepEqual(contents, tt.wantContents) {
+			t.Errorf("%s: backup contents = %q, want %q", tt.name, contents, tt.wantContents)
+		}
+		if active, err := os.ReadFile(path); err != nil || string(active) != tt.wantActive {
+			t.Errorf("%s: active file = %q, %v; want %q", tt.name, active, err, tt.wantActive)
+		}
+	}
+}
+
+func TestFileRotateKeepsBackupsOfOneMillisecondApart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	clock := &testClock{t: time.Date(2024, 1, 2, 10, 0, 0, 0, time.Local)}
+	f := openFileAt(t, path, FileOptions{}, clock)
+	for i := 0; i < 3; i++ {
+		if _, err := f.Write([]byte("line" + strconv.Itoa(i) + "\n")); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	names, contents := readBackups(t, path)
+	var want []string
+	for i := 0; i < 3; i++ {
+		want = append(want, filepath.Base(backupName(path, clock.Now().Add(time.Duration(i)*time.Millisecond)))+".gz")
+	}
+	if !reflect.DeepEqual(names, want) || !reflect.DeepEqual(contents, []string{"line0\n", "line1\n", "line2\n"}) {
+		t.Errorf("backups = %q with %q, want %q", names, contents, want)
+	}
+}
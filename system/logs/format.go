@@ -0,0 +1,145 @@
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formatter turns an entry into the bytes written by a sink, including the trailing newline.
+type Formatter func(e Entry) []byte
+
+// FormatText formats an entry as a single plain text line terminated by a newline:
+//
+//	2024-01-02T15:04:05.000Z INFO message key=value other="quoted value"
+//
+// Like the other formats, it leaves out the time of an entry whose time is zero.
+func FormatText(e Entry) []byte {
+	var b strings.Builder
+	if !e.Time.IsZero() {
+		b.WriteString(e.Time.Format(TimeFormat))
+		b.WriteByte(' ')
+	}
+	b.WriteString(e.Level.String())
+	b.WriteByte(' ')
+	b.WriteString(e.Message)
+	for _, field := range e.Fields {
+		b.WriteByte(' ')
+		b.WriteString(field.Key)
+		b.WriteByte('=')
+		b.WriteString(quoteValue(valueString(field.Value)))
+	}
+	b.WriteByte('\n')
+	return []byte(b.String())
+}
+
+// valueString converts a field value into its text representation.
+func valueString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		return v
+	case time.Time:
+		return v.Format(TimeFormat)
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(value)
+}
+
+// quoteValue quotes a value when it is empty or contains spaces, quotes, '=' or control characters.
+func quoteValue(value string) string {
+	if value == "" {
+		return `""`
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '"' || r == '=' || r == 0x7f {
+			return strconv.Quote(value)
+		}
+	}
+	return value
+}
+
+// FormatLogfmt formats an entry as a logfmt line terminated by a newline:
+//
+//	time=2024-01-02T15:04:05.000Z level=INFO msg="request served" path=/ status=200
+func FormatLogfmt(e Entry) []byte {
+	var b strings.Builder
+	if !e.Time.IsZero() {
+		b.WriteString("time=")
+		b.WriteString(e.Time.Format(TimeFormat))
+		b.WriteByte(' ')
+	}
+	b.WriteString("level=")
+	b.WriteString(e.Level.String())
+	b.WriteString(" msg=")
+	b.WriteString(quoteValue(e.Message))
+	for _, field := range e.Fields {
+		b.WriteByte(' ')
+		b.WriteString(logfmtKey(field.Key))
+		b.WriteByte('=')
+		b.WriteString(quoteValue(valueString(field.Value)))
+	}
+	b.WriteByte('\n')
+	return []byte(b.String())
+}
+
+// FormatJSON formats an entry as a JSON object on a single line, with the same
+// "time", "level" and "msg" keys used by slog.JSONHandler:
+//
+//	{"time":"2024-01-02T15:04:05.000Z","level":"INFO","msg":"request served","status":200}
+func FormatJSON(e Entry) []byte {
+	var b strings.Builder
+	b.WriteByte('{')
+	if !e.Time.IsZero() {
+		b.WriteString(`"time":`)
+		b.Write(jsonValue(e.Time.Format(TimeFormat)))
+		b.WriteByte(',')
+	}
+	b.WriteString(`"level":`)
+	b.Write(jsonValue(e.Level.String()))
+	b.WriteString(`,"msg":`)
+	b.Write(jsonValue(e.Message))
+	for _, field := range e.Fields {
+		b.WriteByte(',')
+		b.Write(jsonValue(field.Key))
+		b.WriteByte(':')
+		b.Write(jsonValue(field.Value))
+	}
+	b.WriteString("}\n")
+	return []byte(b.String())
+}
+
+// jsonValue encodes a field value as JSON. Errors are written as their message, and values
+// that cannot be encoded are written as their text representation.
+func jsonValue(value any) []byte {
+	switch v := value.(type) {
+	case error:
+		value = v.Error()
+	case time.Duration:
+		value = v.String()
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(valueString(value))
+	}
+	return data
+}
+
+// logfmtKey replaces the characters that would break a logfmt key.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f {
+			return '_'
+		}
+		return r
+	}, key)
+}
@@ -0,0 +1,59 @@
+package logs
+
+import (
+	"context"
+	"log/slog"
+)
+
+// handler adapts a Logger to the slog.Handler interface.
+// Attributes inside groups are flattened into fields with dotted keys, e.g. "request.id".
+type handler struct {
+	logger *Logger
+	group  string
+	fields []Field
+}
+
+// Enabled reports whether the logger writes records at the given level.
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.Enabled(Level(level))
+}
+
+// Handle converts the record into an Entry, redacts it and writes it to the logger's sink.
+func (h *handler) Handle(_ context.Context, r slog.Record) error {
+	fields := make([]Field, 0, len(h.logger.fields)+len(h.fields)+r.NumAttrs())
+	fields = append(fields, h.logger.fields...)
+	fields = append(fields, h.fields...)
+	r.Attrs(func(attr slog.Attr) bool {
+		fields = appendAttr(fields, h.group, attr)
+		return true
+	})
+
+	// A zero time is kept: slog handlers leave out the time of records that have none.
+	return h.logger.sink.WriteEntry(redact(Entry{
+		Time:    r.Time,
+		Level:   Level(r.Level),
+		Message: r.Message,
+		Fields:  fields,
+	}))
+}
+
+// WithAttrs returns a handler that adds the attributes to every record.
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := append([]Field(nil), h.fields...)
+	for _, attr := range attrs {
+		fields = appendAttr(fields, h.group, attr)
+	}
+	return &handler{logger: h.logger, group: h.group, fields: fields}
+}
+
+// WithGroup returns a handler that nests the attributes of later records under name.
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	group := name
+	if h.group != "" {
+		group = h.group + "." + name
+	}
+	return &handler{logger: h.logger, group: group, fields: h.fields}
+}
@@ -0,0 +1,85 @@
+package logs
+
+import (
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"testing/slogtest"
+)
+
+// captureSink keeps the entries written to it.
+type captureSink struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func (s *captureSink) WriteEntry(e Entry) error {
+	s.mu.Lock()
+	s.entries = append(s.entries, e)
+	s.mu.Unlock()
+	return nil
+}
+
+// nestedFields rebuilds the groups that the handler flattens into dotted keys.
+func nestedFields(e Entry) map[string]any {
+	m := map[string]any{
+		slog.LevelKey:   slog.Level(e.Level),
+		slog.MessageKey: e.Message,
+	}
+	if !e.Time.IsZero() {
+		m[slog.TimeKey] = e.Time
+	}
+	for _, field := range e.Fields {
+		group := m
+		keys := strings.Split(field.Key, ".")
+		for _, key := range keys[:len(keys)-1] {
+			inner, ok := group[key].(map[string]any)
+			if !ok {
+				inner = make(map[string]any)
+				group[key] = inner
+			}
+			group = inner
+		}
+		group[keys[len(keys)-1]] = field.Value
+	}
+	return m
+}
+
+func TestHandlerSlogtest(t *testing.T) {
+	sink := &captureSink{}
+	h := New(sink, LevelDebug).Handler()
+	err := slogtest.TestHandler(h, func() []map[string]any {
+		var results []map[string]any
+		for _, e := range sink.entries {
+			results = append(results, nestedFields(e))
+		}
+		return results
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandlerGroups(t *testing.T) {
+	sink := &captureSink{}
+	logger := New(sink, LevelInfo).With("app", "api")
+	h := logger.Handler().WithAttrs([]slog.Attr{slog.String("host", "web1")})
+	slog.New(h).WithGroup("request").With("id", 7).WithGroup("user").Info("served", "name", "ana", slog.Group("empty"))
+	slog.New(h).WithGroup("unused").Debug("below the level")
+	slog.New(h).WithGroup("unused").Warn("no attributes")
+
+	want := [][]Field{
+		{{"app", "api"}, {"host", "web1"}, {"request.id", int64(7)}, {"request.user.name", "ana"}},
+		{{"app", "api"}, {"host", "web1"}},
+	}
+	if len(sink.entries) != len(want) {
+		t.Fatalf("entries = %+v, want %d", sink.entries, len(want))
+	}
+	for i, e := range sink.entries {
+		if !reflect.DeepEqual(e.Fields, want[i]) {
+			t.Errorf("entry %d fields = %+v, want %+v", i, e.Fields, want[i])
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+const defaultJournaldSocket = "/run/systemd/journal/socket"
+
+// reservedJournalFields are the field names written by JournaldSink itself or set by journald
+// from the syslog protocol. Entry fields with these names are prefixed with "FIELD_" so that
+// they do not duplicate them.
+var reservedJournalFields = map[string]bool{
+	"MESSAGE":           true,
+	"PRIORITY":          true,
+	"SYSLOG_IDENTIFIER": true,
+	"SYSLOG_TIMESTAMP":  true,
+	"SYSLOG_FACILITY":   true,
+	"SYSLOG_PID":        true,
+	"SYSLOG_RAW":        true,
+}
+
+// JournaldOptions configures a JournaldSink.
+type JournaldOptions struct {
+	Socket     string // Path of the journald socket. Defaults to "/run/systemd/journal/socket".
+	Identifier string // SYSLOG_IDENTIFIER of the entries. Defaults to the name of the running program.
+}
+
+// JournaldSink writes entries to the systemd journal with its native protocol.
+// Entry fields are sent as journal fields, with names converted to upper case, e.g. "user_id" becomes USER_ID.
+// Fields that would collide with the ones the sink writes, such as "message", become FIELD_MESSAGE.
+// Entries too large for a datagram are passed to journald in a memory file descriptor.
+type JournaldSink struct {
+	opts JournaldOptions
+	conn *net.UnixConn
+	addr *net.UnixAddr
+}
+
+// NewJournaldSink returns a sink that writes to the journald socket described by opts.
+func NewJournaldSink(opts JournaldOptions) (*JournaldSink, error) {
+	if opts.Socket == "" {
+		opts.Socket = defaultJournaldSocket
+	}
+	if opts.Identifier == "" {
+		opts.Identifier = filepath.Base(os.Args[0])
+	}
+	if _, err := os.Stat(opts.Socket); err != nil {
+		return nil, fmt.Errorf("journald socket not available: %v", err)
+	}
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("error creating journald socket: %v", err)
+	}
+	return &JournaldSink{
+		opts: opts,
+		conn: conn,
+		addr: &net.UnixAddr{Name: opts.Socket, Net: "unixgram"},
+	}, nil
+}
+
+// WriteEntry sends the entry to journald.
+func (j *JournaldSink) WriteEntry(e Entry) error {
+	data := j.format(e)
+	_, _, err := j.conn.WriteMsgUnix(data, nil, j.addr)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+		return j.writeLarge(data)
+	}
+	return err
+}
+
+// Close closes the socket used to talk to journald.
+func (j *JournaldSink) Close() error {
+	return j.conn.Close()
+}
+
+// writeLarge writes the entry to a sealed memfd and passes its descriptor to journald,
+// as the native protocol requires for entries larger than a datagram.
+func (j *JournaldSink) writeLarge(data []byte) error {
+	fd, err := unix.MemfdCreate("lcme-journal", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return fmt.Errorf("error creating journald memfd: %v", err)
+	}
+	file := os.NewFile(uintptr(fd), "lcme-journal")
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		return err
+	}
+	if _, err := unix.FcntlInt(file.Fd(), unix.F_ADD_SEALS, unix.F_SEAL_SHRINK|unix.F_SEAL_GROW|unix.F_SEAL_WRITE|unix.F_SEAL_SEAL); err != nil {
+		return fmt.Errorf("error sealing journald memfd: %v", err)
+	}
+	_, _, err = j.conn.WriteMsgUnix(nil, unix.UnixRights(int(file.Fd())), j.addr)
+	return err
+}
+
+// format serializes the entry in the journald native protocol.
+// Values containing newlines use the binary form: the name, a newline, the length as a
+// little-endian uint64 and the raw value.
+func (j *JournaldSink) format(e Entry) []byte {
+	var b bytes.Buffer
+	appendJournalField(&b, "MESSAGE", e.Message)
+	appendJournalField(&b, "PRIORITY", strconv.Itoa(syslogSeverity(e.Level)))
+	appendJournalField(&b, "SYSLOG_IDENTIFIER", j.opts.Identifier)
+	if !e.Time.IsZero() {
+		appendJournalField(&b, "SYSLOG_TIMESTAMP", e.Time.Format(TimeFormat))
+	}
+	for _, field := range e.Fields {
+		appendJournalField(&b, journalFieldName(field.Key), valueString(field.Value))
+	}
+	return b.Bytes()
+}
+
+// appendJournalField appends one field in the native protocol.
+func appendJournalField(b *bytes.Buffer, name, value string) {
+	b.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteByte('\n')
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	b.Write(size[:])
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+// journalFieldName converts a field key into a valid journal field name: upper-case letters,
+// digits and underscores, not starting with an underscore or a digit, at most 64 characters.
+// Names reserved by the sink are prefixed with "FIELD_".
+func journalFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, key)
+	name = strings.TrimLeft(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') || reservedJournalFields[name] {
+		name = "FIELD_" + name
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
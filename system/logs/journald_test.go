@@ -0,0 +1,148 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// journalField is a field decoded from the journald native protocol.
+type journalField struct {
+	name, value string
+}
+
+// parseJournalFields decodes the native protocol: "NAME=value\n", or "NAME\n", a little-endian
+// uint64 length, the raw value and "\n" for values with newlines.
+func parseJournalFields(t *testing.T, data []byte) []journalField {
+	t.Helper()
+	var fields []journalField
+	for len(data) > 0 {
+		line := data
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			line = data[:i]
+		}
+		if name, value, ok := bytes.Cut(line, []byte("=")); ok {
+			fields = append(fields, journalField{string(name), string(value)})
+			data = data[min(len(line)+1, len(data)):]
+			continue
+		}
+		data = data[len(line)+1:]
+		if len(data) < 8 {
+			t.Fatalf("truncated binary field %q", line)
+		}
+		size := binary.LittleEndian.Uint64(data[:8])
+		data = data[8:]
+		if uint64(len(data)) < size+1 || data[size] != '\n' {
+			t.Fatalf("invalid binary field %q", line)
+		}
+		fields = append(fields, journalField{string(line), string(data[:size])})
+		data = data[size+1:]
+	}
+	return fields
+}
+
+// listenJournald starts a stand-in journald socket and a sink writing to it.
+func listenJournald(t *testing.T) (*net.UnixConn, *JournaldSink) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	listener, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	sink, err := NewJournaldSink(JournaldOptions{Socket: path, Identifier: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sink.Close() })
+	listener.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return listener, sink
+}
+
+func TestJournaldSinkFields(t *testing.T) {
+	listener, sink := listenJournald(t)
+	entry := Entry{
+		Time:    time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+		Level:   LevelError,
+		Message: "query failed",
+		Fields: []Field{
+			{Key: "user_id", Value: 42},
+			{Key: "sql", Value: "SELECT 1\nFROM t"},
+			{Key: "message", Value: "user message"},
+			{Key: "priority", Value: "high"},
+			{Key: "9lives", Value: true},
+		},
+	}
+	if err := sink.WriteEntry(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 4096)
+	n, err := listener.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []journalField{
+		{"MESSAGE", "query failed"},
+		{"PRIORITY", "3"},
+		{"SYSLOG_IDENTIFIER", "app"},
+		{"SYSLOG_TIMESTAMP", "2024-01-02T15:04:05.000Z"},
+		{"USER_ID", "42"},
+		{"SQL", "SELECT 1\nFROM t"},
+		{"FIELD_MESSAGE", "user message"},
+		{"FIELD_PRIORITY", "high"},
+		{"FIELD_9LIVES", "true"},
+	}
+	got := parseJournalFields(t, buf[:n])
+	if len(got) != len(want) {
+		t.Fatalf("fields = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("field %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestJournaldSinkLargeEntry(t *testing.T) {
+	listener, sink := listenJournald(t)
+	message := strings.Repeat("x", 4*1024*1024) // Larger than any datagram.
+	if err := sink.WriteEntry(Entry{Time: time.Now(), Level: LevelInfo, Message: message}); err != nil {
+		t.Fatal(err)
+	}
+
+	oob := make([]byte, unix.CmsgSpace(4))
+	_, oobn, _, _, err := listener.ReadMsgUnix(nil, oob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages, err := unix.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(messages) != 1 {
+		t.Fatalf("no file descriptor received: %v", err)
+	}
+	fds, err := unix.ParseUnixRights(&messages[0])
+	if err != nil || len(fds) != 1 {
+		t.Fatalf("no file descriptor received: %v", err)
+	}
+	file := os.NewFile(uintptr(fds[0]), "memfd")
+	defer file.Close()
+
+	// The descriptor shares the sender's file offset, which is at the end; journald reads it with mmap.
+	data, err := io.ReadAll(io.NewSectionReader(file, 0, 1<<30))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := parseJournalFields(t, data)
+	if len(fields) == 0 || fields[0].name != "MESSAGE" || fields[0].value != message {
+		t.Error("the memfd does not hold the entry")
+	}
+}
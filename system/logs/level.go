@@ -0,0 +1,40 @@
+package logs
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Level is the severity of a log entry.
+// Its values match the log/slog levels, so a Level can be converted to slog.Level and back directly.
+type Level int
+
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+)
+
+// String returns the upper-case name of the level, such as "INFO" or "WARN".
+// Levels between the named ones are printed the same way slog does, e.g. "INFO+2".
+func (l Level) String() string {
+	return slog.Level(l).String()
+}
+
+// ParseLevel converts a level name (debug, info, warn, warning or error) into a Level.
+// The comparison is case insensitive.
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "DEBUG":
+		return LevelDebug, nil
+	case "INFO":
+		return LevelInfo, nil
+	case "WARN", "WARNING":
+		return LevelWarn, nil
+	case "ERROR":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("unknown log level: %s", name)
+}
@@ -0,0 +1,117 @@
+package logs
+
+import (
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// Sink is a destination for log entries, such as a .log file.
+type Sink interface {
+	WriteEntry(e Entry) error
+}
+
+// Logger writes leveled, structured entries to a Sink.
+// Entries below the minimum level are discarded. The level can be changed at any time
+// with SetLevel, and the change is seen by every Logger derived with With.
+type Logger struct {
+	sink   Sink
+	level  *atomic.Int64
+	fields []Field
+}
+
+// New returns a Logger that writes entries at or above level to sink.
+func New(sink Sink, level Level) *Logger {
+	l := &Logger{sink: sink, level: new(atomic.Int64)}
+	l.level.Store(int64(level))
+	return l
+}
+
+// SetLevel changes the minimum level of the logger.
+func (l *Logger) SetLevel(level Level) {
+	l.level.Store(int64(level))
+}
+
+// Level returns the current minimum level of the logger.
+func (l *Logger) Level() Level {
+	return Level(l.level.Load())
+}
+
+// Enabled reports whether entries at the given level are written.
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.Level()
+}
+
+// With returns a Logger that adds the given fields to every entry.
+// The arguments follow the same rules as the ones passed to Info.
+func (l *Logger) With(args ...any) *Logger {
+	fields := fieldsFromArgs(args)
+	if len(fields) == 0 {
+		return l
+	}
+	return &Logger{
+		sink:   l.sink,
+		level:  l.level,
+		fields: append(append([]Field(nil), l.fields...), fields...),
+	}
+}
+
+// Debug logs a message at LevelDebug.
+func (l *Logger) Debug(msg string, args ...any) {
+	l.Log(LevelDebug, msg, args...)
+}
+
+// Info logs a message at LevelInfo.
+// The arguments can be Field or slog.Attr values, or alternating key/value pairs:
+//
+//	logger.Info("request served", "path", "/", "status", 200)
+func (l *Logger) Info(msg string, args ...any) {
+	l.Log(LevelInfo, msg, args...)
+}
+
+// Warn logs a message at LevelWarn.
+func (l *Logger) Warn(msg string, args ...any) {
+	l.Log(LevelWarn, msg, args...)
+}
+
+// Error logs a message at LevelError.
+func (l *Logger) Error(msg string, args ...any) {
+	l.Log(LevelError, msg, args...)
+}
+
+// Log logs a message at the given level.
+func (l *Logger) Log(level Level, msg string, args ...any) {
+	if !l.Enabled(level) {
+		return
+	}
+	fields := l.fields
+	if len(args) > 0 {
+		fields = append(append([]Field(nil), l.fields...), fieldsFromArgs(args)...)
+	}
+	l.write(Entry{Time: time.Now(), Level: level, Message: msg, Fields: fields})
+}
+
+// write redacts the entry and sends it to the sink. Errors are reported on stderr since there is no caller to return them to.
+func (l *Logger) write(e Entry) {
+	if err := l.sink.WriteEntry(redact(e)); err != nil {
+		reportError("Error writing log entry:", err)
+	}
+}
+
+// Close closes the logger's sink if it implements io.Closer, e.g. a File or a MultiSink.
+// Loggers derived with With share the sink, so it only needs to be called once.
+func (l *Logger) Close() error {
+	if closer, ok := l.sink.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Handler returns a slog.Handler that writes to the same sink and honors the same minimum level,
+// so the logger can be used through the standard slog API:
+//
+//	slog.SetDefault(slog.New(logger.Handler()))
+func (l *Logger) Handler() slog.Handler {
+	return &handler{logger: l}
+}
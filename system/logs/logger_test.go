@@ -0,0 +1,115 @@
+package logs
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoggerLevels(t *testing.T) {
+	sink := &captureSink{}
+	logger := New(sink, LevelWarn)
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+	logger.Log(LevelWarn+2, "custom")
+
+	logger.SetLevel(LevelDebug)
+	logger.Debug("debug after SetLevel")
+
+	var got []string
+	for _, e := range sink.entries {
+		got = append(got, e.Level.String()+" "+e.Message)
+	}
+	want := []string{"WARN warn", "ERROR error", "WARN+2 custom", "DEBUG debug after SetLevel"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %q, want %q", got, want)
+	}
+	if logger.Level() != LevelDebug || !logger.Enabled(LevelDebug) || logger.Enabled(LevelDebug-1) {
+		t.Errorf("Level() = %v after SetLevel(LevelDebug)", logger.Level())
+	}
+}
+
+func TestLoggerSetLevelConcurrently(t *testing.T) {
+	sink := &captureSink{}
+	logger := New(sink, LevelInfo)
+	child := logger.With("worker", 1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				logger.SetLevel(Level(j%3*4 - 4)) // DEBUG, INFO or WARN.
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				child.Info("tick")
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The derived logger shares the level.
+	logger.SetLevel(LevelError)
+	before := len(sink.entries)
+	child.Warn("hidden")
+	if len(sink.entries) != before {
+		t.Error("a logger derived with With ignored SetLevel on its parent")
+	}
+}
+
+func TestLoggerWith(t *testing.T) {
+	sink := &captureSink{}
+	logger := New(sink, LevelInfo)
+	if logger.With() != logger {
+		t.Error("With without fields returned a new logger")
+	}
+	a := logger.With("service", "api")
+	b := a.With(Field{Key: "region", Value: "eu"}, "odd")
+	a.Info("from a", "status", 200)
+	b.Info("from b")
+	logger.Info("from root")
+
+	want := [][]Field{
+		{{"service", "api"}, {"status", 200}},
+		{{"service", "api"}, {"region", "eu"}, {"!BADKEY", "odd"}},
+		nil,
+	}
+	if len(sink.entries) != len(want) {
+		t.Fatalf("entries = %+v, want %d", sink.entries, len(want))
+	}
+	for i, e := range sink.entries {
+		if !reflect.DeepEqual(e.Fields, want[i]) {
+			t.Errorf("%s: fields = %+v, want %+v", e.Message, e.Fields, want[i])
+		}
+	}
+}
+
+func TestFormatsWithoutTime(t *testing.T) {
+	e := Entry{Level: LevelInfo, Message: "hi", Fields: []Field{{"k", "v"}}}
+	tests := []struct {
+		format Formatter
+		want   string
+	}{
+		{FormatText, "INFO hi k=v\n"},
+		{FormatLogfmt, "level=INFO msg=hi k=v\n"},
+		{FormatJSON, `{"level":"INFO","msg":"hi","k":"v"}` + "\n"},
+	}
+	for _, tt := range tests {
+		if got := string(tt.format(e)); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+
+	e.Time = time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	if got := string(FormatText(e)); !strings.HasPrefix(got, "2024-01-02T15:04:05.000Z INFO") {
+		t.Errorf("FormatText = %q", got)
+	}
+}
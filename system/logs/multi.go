@@ -0,0 +1,169 @@
+package logs
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultRouteQueueSize = 1024
+
+// WriterSink writes formatted entries to an io.Writer, such as os.Stderr.
+type WriterSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format Formatter
+}
+
+// NewWriterSink returns a sink that writes entries to w in the given format.
+// A nil format selects FormatText.
+func NewWriterSink(w io.Writer, format Formatter) *WriterSink {
+	if format == nil {
+		format = FormatText
+	}
+	return &WriterSink{w: w, format: format}
+}
+
+// WriteEntry formats the entry and writes it to the underlying writer.
+func (s *WriterSink) WriteEntry(e Entry) error {
+	data := s.format(e)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.w.Write(data)
+	return err
+}
+
+// Route sends the entries of a MultiSink to one sink.
+// The format of the entries is the one of the sink itself, e.g. FileOptions.Format or the
+// format given to NewWriterSink.
+type Route struct {
+	Sink      Sink
+	Level     Level              // Minimum level sent to the sink.
+	Filter    func(e Entry) bool // Optional; entries for which it returns false are skipped.
+	QueueSize int                // Entries waiting for the sink. Defaults to 1024.
+	Overflow  OverflowPolicy     // What to do when the queue is full. By default, File sinks block and the others drop.
+}
+
+// route is a Route with the queue and goroutine that feed its sink.
+type route struct {
+	Route
+	block   bool
+	queue   chan Entry
+	dropped atomic.Uint64
+}
+
+// MultiSink sends every entry to several sinks, each with its own level and filter.
+// Every sink is fed by its own goroutine and queue, so a slow or failing sink does not
+// delay the others until its queue is full. Then entries for a route that drops are
+// discarded and counted, while a route that blocks makes WriteEntry wait for room,
+// so that no line meant for a log file is lost. Sink errors are passed to the error handler.
+type MultiSink struct {
+	routes  []*route
+	onError atomic.Pointer[func(sink Sink, err error)]
+
+	closeMu sync.RWMutex
+	closed  bool
+	workers sync.WaitGroup
+}
+
+// NewMultiSink starts a sink that fans entries out to the given routes.
+// Errors are printed on stderr until SetErrorHandler is called.
+func NewMultiSink(routes ...Route) *MultiSink {
+	m := &MultiSink{}
+	m.SetErrorHandler(func(_ Sink, err error) {
+		reportError("Error writing log entry:", err)
+	})
+	for _, r := range routes {
+		if r.QueueSize <= 0 {
+			r.QueueSize = defaultRouteQueueSize
+		}
+		_, isFile := r.Sink.(*File)
+		block := r.Overflow == OverflowBlock || (r.Overflow == OverflowDefault && isFile)
+		rt := &route{Route: r, block: block, queue: make(chan Entry, r.QueueSize)}
+		m.routes = append(m.routes, rt)
+		m.workers.Add(1)
+		go m.run(rt)
+	}
+	return m
+}
+
+// SetErrorHandler sets the function called when a sink fails to write an entry.
+// It can be called at any time; a nil fn ignores the errors.
+func (m *MultiSink) SetErrorHandler(fn func(sink Sink, err error)) {
+	if fn == nil {
+		fn = func(Sink, error) {}
+	}
+	m.onError.Store(&fn)
+}
+
+// WriteEntry queues the entry for every route whose level and filter accept it.
+// When the queue of a route is full, it waits for room or drops the entry, according to the route's Overflow.
+func (m *MultiSink) WriteEntry(e Entry) error {
+	m.closeMu.RLock()
+	defer m.closeMu.RUnlock()
+	if m.closed {
+		return ErrClosed
+	}
+
+	for _, r := range m.routes {
+		if e.Level < r.Level || (r.Filter != nil && !r.Filter(e)) {
+			continue
+		}
+		if r.block {
+			r.queue <- e
+			continue
+		}
+		select {
+		case r.queue <- e:
+		default:
+			r.dropped.Add(1)
+		}
+	}
+	return nil
+}
+
+// Dropped returns the number of entries dropped because the queue was full for each route,
+// in the order of the routes. It is always zero for routes that block.
+func (m *MultiSink) Dropped() []uint64 {
+	dropped := make([]uint64, len(m.routes))
+	for i, r := range m.routes {
+		dropped[i] = r.dropped.Load()
+	}
+	return dropped
+}
+
+// Close writes the queued entries, stops the goroutines and closes every sink that implements io.Closer.
+func (m *MultiSink) Close() error {
+	m.closeMu.Lock()
+	if m.closed {
+		m.closeMu.Unlock()
+		return nil
+	}
+	m.closed = true
+	for _, r := range m.routes {
+		close(r.queue)
+	}
+	m.closeMu.Unlock()
+
+	m.workers.Wait()
+	var errs []error
+	for _, r := range m.routes {
+		if closer, ok := r.Sink.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// run writes the queued entries of a route to its sink.
+func (m *MultiSink) run(r *route) {
+	defer m.workers.Done()
+	for e := range r.queue {
+		if err := r.Sink.WriteEntry(e); err != nil {
+			(*m.onError.Load())(r.Sink, err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package logs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingSink waits on release before accepting each entry, and counts them.
+type blockingSink struct {
+	release chan struct{}
+	written atomic.Int64
+}
+
+func (s *blockingSink) WriteEntry(Entry) error {
+	<-s.release
+	s.written.Add(1)
+	return nil
+}
+
+func TestMultiSinkFileRouteBlocks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	file, err := OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMultiSink(Route{Sink: file, QueueSize: 1})
+	for i := 0; i < 100; i++ {
+		if err := m.WriteEntry(Entry{Time: time.Now(), Level: LevelInfo, Message: "line"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if dropped := m.Dropped(); dropped[0] != 0 {
+		t.Errorf("file route dropped %d entries", dropped[0])
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 100 {
+		t.Errorf("file has %d lines, want 100", lines)
+	}
+}
+
+func TestMultiSinkOverflowPolicies(t *testing.T) {
+	dropping := &blockingSink{release: make(chan struct{})}
+	blocking := &blockingSink{release: make(chan struct{})}
+	m := NewMultiSink(
+		Route{Sink: dropping, QueueSize: 1},
+		Route{Sink: blocking, QueueSize: 1, Overflow: OverflowBlock},
+	)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			m.WriteEntry(Entry{Level: LevelInfo})
+		}
+	}()
+	go func() {
+		// Let the blocking route through one entry at a time; the dropping one stays stuck.
+		for i := 0; i < 10; i++ {
+			blocking.release <- struct{}{}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("WriteEntry did not return")
+	}
+
+	close(dropping.release)
+	m.Close()
+	if got := blocking.written.Load(); got != 10 {
+		t.Errorf("blocking route wrote %d entries, want 10", got)
+	}
+	dropped := m.Dropped()
+	if dropped[1] != 0 {
+		t.Errorf("blocking route dropped %d entries", dropped[1])
+	}
+	if dropped[0] == 0 || int64(dropped[0])+dropping.written.Load() != 10 {
+		t.Errorf("dropping route wrote %d and dropped %d entries, want 10 in total", dropping.written.Load(), dropped[0])
+	}
+}
+
+type failingSink struct{}
+
+func (failingSink) WriteEntry(Entry) error { return errors.New("sink failed") }
+
+func TestMultiSinkSetErrorHandlerWhileWriting(t *testing.T) {
+	m := NewMultiSink(Route{Sink: failingSink{}, Overflow: OverflowBlock})
+	var errs atomic.Int64
+	m.SetErrorHandler(func(Sink, error) {})
+	go func() {
+		for i := 0; i < 100; i++ {
+			m.WriteEntry(Entry{Level: LevelInfo})
+		}
+	}()
+	m.SetErrorHandler(func(Sink, error) { errs.Add(1) })
+	for i := 0; i < 100; i++ {
+		m.WriteEntry(Entry{Level: LevelInfo})
+	}
+	m.Close()
+	if errs.Load() == 0 {
+		t.Error("the error handler was not called")
+	}
+}
@@ -0,0 +1,258 @@
+package logs
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query selects lines from a log file and its rotated archives.
+// Zero values do not filter.
+type Query struct {
+	Since    time.Time // Only lines at or after this time.
+	Until    time.Time // Only lines before this time.
+	Levels   []Level   // Only lines with one of these levels.
+	Contains string    // Only lines that contain this substring.
+	Limit    int       // Keep only the newest Limit matches.
+	Archives bool      // Also read the rotated files, including the gzip-compressed ones.
+}
+
+// Record is a log line matched by a query.
+// Time is zero and Level is LevelInfo when the line does not carry them.
+type Record struct {
+	File    string
+	Line    string
+	Time    time.Time
+	Level   Level
+	Message string
+}
+
+// parsedLine is the result of parsing a line, recording which parts were found.
+type parsedLine struct {
+	Record
+	hasTime  bool
+	hasLevel bool
+}
+
+// QueryFile returns the lines of the log file at path that match q, oldest first.
+// Lines can be JSON objects, such as the ones written by slog.JSONHandler, or plain text lines
+// starting with a timestamp and a level, such as the ones written by Logger.
+// Lines without a timestamp only match when no time range is set, and lines without
+// a level only match when no levels are set.
+func QueryFile(path string, q Query) ([]Record, error) {
+	var files []string
+	if q.Archives {
+		backups, err := listBackups(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range backups {
+			// Not skipped by rotation time: a clock stepped back can leave newer lines in an older file.
+			files = append(files, b.path)
+		}
+	}
+	files = append(files, path)
+
+	var records []Record
+	for _, file := range files {
+		err := scanLogFile(file, func(line string) {
+			parsed := parseLogLine(line)
+			if !q.matches(parsed) {
+				return
+			}
+			parsed.File = file
+			records = append(records, parsed.Record)
+			if q.Limit > 0 && len(records) > 2*q.Limit {
+				records = append(records[:0], records[len(records)-q.Limit:]...)
+			}
+		})
+		if os.IsNotExist(err) && (file != path || len(files) > 1) {
+			// Archives may be removed while they are read, and the active file may not exist right after a rotation.
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	if q.Limit > 0 && len(records) > q.Limit {
+		records = records[len(records)-q.Limit:]
+	}
+	return records, nil
+}
+
+// matches reports whether a parsed line passes every filter of the query.
+func (q Query) matches(p parsedLine) bool {
+	if q.Contains != "" && !strings.Contains(p.Line, q.Contains) {
+		return false
+	}
+	if !q.Since.IsZero() || !q.Until.IsZero() {
+		if !p.hasTime {
+			return false
+		}
+		if !q.Since.IsZero() && p.Time.Before(q.Since) {
+			return false
+		}
+		if !q.Until.IsZero() && !p.Time.Before(q.Until) {
+			return false
+		}
+	}
+	if len(q.Levels) > 0 {
+		if !p.hasLevel {
+			return false
+		}
+		found := false
+		for _, level := range q.Levels {
+			if level == p.Level {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// scanLogFile calls fn for every line of a log file, decompressing it when it ends in .gz.
+func scanLogFile(path string, fn func(line string)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	buffered := bufio.NewReader(reader)
+	for {
+		line, err := buffered.ReadString('\n')
+		if line != "" {
+			fn(strings.TrimSuffix(line, "\n"))
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// parseLogLine extracts the time, level and message of a JSON or plain text log line.
+func parseLogLine(line string) parsedLine {
+	p := parsedLine{Record: Record{Line: line, Level: LevelInfo, Message: line}}
+	if strings.HasPrefix(strings.TrimSpace(line), "{") && parseJSONLine(line, &p) {
+		return p
+	}
+	parseTextLine(line, &p)
+	return p
+}
+
+// parseJSONLine reads the common time, level and message keys of a JSON log line.
+func parseJSONLine(line string, p *parsedLine) bool {
+	var object map[string]any
+	if err := json.Unmarshal([]byte(line), &object); err != nil {
+		return false
+	}
+	for _, key := range []string{"time", "ts", "timestamp", "@timestamp"} {
+		if value, ok := object[key].(string); ok {
+			if t, ok := parseLogTime(value); ok {
+				p.Time, p.hasTime = t, true
+				break
+			}
+		}
+	}
+	for _, key := range []string{"level", "lvl", "severity"} {
+		if value, ok := object[key].(string); ok {
+			if level, ok := parseLogLevel(value); ok {
+				p.Level, p.hasLevel = level, true
+				break
+			}
+		}
+	}
+	for _, key := range []string{"msg", "message"} {
+		if value, ok := object[key].(string); ok {
+			p.Message = value
+			break
+		}
+	}
+	return true
+}
+
+// parseTextLine reads a leading timestamp and level from a plain text log line, e.g.
+// "2024-01-02T15:04:05.000Z INFO message" or "2024/01/02 15:04:05 [WARN] message".
+func parseTextLine(line string, p *parsedLine) {
+	rest := strings.TrimSpace(line)
+	fields := strings.Fields(rest)
+	if len(fields) > 0 {
+		if t, ok := parseLogTime(fields[0]); ok {
+			p.Time, p.hasTime = t, true
+			rest = strings.TrimSpace(strings.TrimPrefix(rest, fields[0]))
+		} else if len(fields) > 1 {
+			if t, ok := parseLogTime(fields[0] + " " + fields[1]); ok {
+				p.Time, p.hasTime = t, true
+				rest = strings.TrimSpace(strings.TrimPrefix(rest, fields[0]))
+				rest = strings.TrimSpace(strings.TrimPrefix(rest, fields[1]))
+			}
+		}
+	}
+
+	if token, _, _ := strings.Cut(rest, " "); token != "" {
+		name := strings.Trim(strings.TrimPrefix(strings.ToLower(token), "level="), "[]:")
+		if level, ok := parseLogLevel(name); ok {
+			p.Level, p.hasLevel = level, true
+			rest = strings.TrimSpace(strings.TrimPrefix(rest, token))
+		}
+	}
+	p.Message = rest
+}
+
+// logTimeFormats are the timestamp layouts recognized at the start of a log line.
+var logTimeFormats = []string{
+	TimeFormat,
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.000",
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05.000000",
+	"2006/01/02 15:04:05",
+}
+
+// parseLogTime parses a timestamp in one of the layouts used by log writers.
+// Layouts without a zone are read in local time.
+func parseLogTime(value string) (time.Time, bool) {
+	for _, layout := range logTimeFormats {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseLogLevel parses a level name, including the slog forms with an offset such as "INFO+2".
+func parseLogLevel(name string) (Level, bool) {
+	base, offset := name, 0
+	if i := strings.IndexAny(name, "+-"); i > 0 {
+		n, err := strconv.Atoi(name[i:])
+		if err != nil {
+			return LevelInfo, false
+		}
+		base, offset = name[:i], n
+	}
+	level, err := ParseLevel(base)
+	if err != nil {
+		return LevelInfo, false
+	}
+	return level + Level(offset), true
+}
@@ -0,0 +1,129 @@
+package logs
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeLogFixture writes lines to path, gzip-compressed when the name ends in .gz.
+func writeLogFixture(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	content := strings.Join(lines, "\n") + "\n"
+	if !strings.HasSuffix(path, ".gz") {
+		if _, err := file.WriteString(content); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	gz := gzip.NewWriter(file)
+	if _, err := gz.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	at := func(hour, minute int) time.Time { return time.Date(2024, 1, 2, hour, minute, 0, 0, time.Local) }
+	line := func(hour, minute int, level, message string) string {
+		return at(hour, minute).Format(TimeFormat) + " " + level + " " + message
+	}
+
+	// Written in a shuffled order: the archives must be read by rotation time, not by name order on disk.
+	writeLogFixture(t, backupName(path, at(11, 0))+".gz",
+		line(10, 30, "INFO", "cache warmed"),
+		line(10, 45, "ERROR", "db timeout"),
+		// Written with a clock ahead of the rotation time, e.g. right before an NTP step back.
+		line(11, 20, "WARN", "clock skew"),
+	)
+	writeLogFixture(t, backupName(path, at(10, 0))+".gz",
+		line(9, 0, "INFO", "booted"),
+	)
+	writeLogFixture(t, backupName(path, at(11, 30)),
+		line(11, 10, "DEBUG", "uncompressed backup"),
+	)
+	writeLogFixture(t, path,
+		line(12, 0, "INFO", "started"),
+		line(12, 5, "DEBUG", "connecting to db"),
+		`{"time":"`+at(12, 10).Format(time.RFC3339Nano)+`","level":"ERROR","msg":"db failed"}`,
+		"no timestamp here",
+	)
+	// Shares the prefix but is not a rotated file.
+	writeLogFixture(t, filepath.Join(dir, "app-old.log"), line(12, 30, "ERROR", "unrelated"))
+
+	tests := []struct {
+		name string
+		q    Query
+		want []string // Messages of the records.
+	}{
+		{"active file only", Query{},
+			[]string{"started", "connecting to db", "db failed", "no timestamp here"}},
+		{"since", Query{Since: at(12, 5)},
+			[]string{"connecting to db", "db failed"}},
+		{"until is exclusive", Query{Until: at(12, 5)},
+			[]string{"started"}},
+		{"levels", Query{Levels: []Level{LevelError, LevelDebug}},
+			[]string{"connecting to db", "db failed"}},
+		{"contains", Query{Contains: "db"},
+			[]string{"connecting to db", "db failed"}},
+		{"archives in time order", Query{Archives: true, Until: at(12, 1)},
+			[]string{"booted", "cache warmed", "db timeout", "clock skew", "uncompressed backup", "started"}},
+		{"archives with levels", Query{Archives: true, Levels: []Level{LevelError}},
+			[]string{"db timeout", "db failed"}},
+		{"backup rotated before since", Query{Archives: true, Since: at(11, 15), Until: at(12, 0)},
+			[]string{"clock skew"}},
+		{"limit keeps the newest", Query{Archives: true, Contains: "db", Limit: 2},
+			[]string{"connecting to db", "db failed"}},
+	}
+	for _, tt := range tests {
+		records, err := QueryFile(path, tt.q)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got []string
+		for _, r := range records {
+			got = append(got, r.Message)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: messages = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestQueryFileRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	when := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	writeLogFixture(t, backupName(path, when.Add(time.Hour))+".gz", when.Format(TimeFormat)+" WARN disk almost full")
+
+	records, err := QueryFile(path, Query{Archives: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Record{{
+		File:    backupName(path, when.Add(time.Hour)) + ".gz",
+		Line:    when.Format(TimeFormat) + " WARN disk almost full",
+		Time:    when,
+		Level:   LevelWarn,
+		Message: "disk almost full",
+	}}
+	if len(records) != 1 || !records[0].Time.Equal(want[0].Time) {
+		t.Fatalf("records = %+v, want %+v", records, want)
+	}
+	records[0].Time = want[0].Time
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %+v, want %+v", records, want)
+	}
+}
@@ -0,0 +1,166 @@
+package logs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GomdimApps/lcme/system/compressfiles"
+)
+
+// RotateInterval selects time-based rotation of a log file.
+type RotateInterval int
+
+const (
+	RotateNever RotateInterval = iota
+	RotateHourly
+	RotateDaily
+)
+
+// backupTimeFormat is the timestamp layout inserted in the names of rotated files,
+// e.g. app.log is rotated to app-2024-01-02T15-04-05.000.log and then to app-2024-01-02T15-04-05.000.log.gz.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// Rotation configures when a File is rotated and how many rotated files are kept.
+type Rotation struct {
+	MaxSize    int64          // Rotate before the file grows beyond this many bytes. Zero disables size rotation.
+	Interval   RotateInterval // Rotate when the hour or day changes.
+	MaxBackups int            // Number of rotated files to keep. Zero keeps all of them.
+}
+
+// enabled reports whether the rotation policy can ever rotate the file.
+func (r Rotation) enabled() bool {
+	return r.MaxSize > 0 || r.Interval != RotateNever
+}
+
+// period returns the start of the rotation period that contains t.
+func (r Rotation) period(t time.Time) time.Time {
+	switch r.Interval {
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+	return time.Time{}
+}
+
+// backup is a rotated log file found next to the active one.
+type backup struct {
+	path       string
+	time       time.Time
+	compressed bool
+}
+
+// backupName returns the name of the rotated file for the active log path at time t.
+func backupName(path string, t time.Time) string {
+	return strings.TrimSuffix(path, ".log") + "-" + t.Format(backupTimeFormat) + ".log"
+}
+
+// listBackups returns the rotated files of the active log path, oldest first.
+// Files that only share the prefix but do not carry a rotation timestamp are ignored.
+func listBackups(path string) ([]backup, error) {
+	dir := filepath.Dir(path)
+	prefix := strings.TrimSuffix(filepath.Base(path), ".log") + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimPrefix(name, prefix)
+		compressed := strings.HasSuffix(stamp, ".log.gz")
+		stamp = strings.TrimSuffix(strings.TrimSuffix(stamp, ".gz"), ".log")
+		t, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{path: filepath.Join(dir, name), time: t, compressed: compressed})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].time.Before(backups[j].time) })
+	return backups, nil
+}
+
+// rotateLocked moves the active log file aside, reopens a new one and starts compressing
+// the rotated file in the background. If the buffer cannot be flushed, the file is not rotated
+// and the buffered lines are kept. The caller must hold f.mu.
+func (f *File) rotateLocked(now time.Time) error {
+	if err := f.flushLocked(f.opts.Sync != SyncNever); err != nil {
+		return fmt.Errorf("error rotating log file: %v", err)
+	}
+	if f.handle != nil {
+		f.handle.Close()
+		f.handle = nil
+	}
+
+	name := backupName(f.path, now)
+	for {
+		// Two rotations within the same millisecond must not overwrite each other.
+		if !fileExists(name) && !fileExists(name+".gz") {
+			break
+		}
+		now = now.Add(time.Millisecond)
+		name = backupName(f.path, now)
+	}
+	renameErr := os.Rename(f.path, name)
+	if err := f.openHandleLocked(); err != nil {
+		return err // The next write opens the file again.
+	}
+	if renameErr != nil && !os.IsNotExist(renameErr) {
+		return fmt.Errorf("error rotating log file: %v", renameErr)
+	}
+
+	f.compressions.Add(1)
+	go func() {
+		defer f.compressions.Done()
+		f.compressBackups()
+	}()
+	return nil
+}
+
+// compressBackups gzips every uncompressed rotated file and removes the oldest ones beyond MaxBackups.
+// Runs are serialized so that two rotations in a row do not compress the same file twice.
+func (f *File) compressBackups() {
+	f.compressMu.Lock()
+	defer f.compressMu.Unlock()
+
+	backups, err := listBackups(f.path)
+	if err != nil {
+		reportError("Error listing rotated log files:", err)
+		return
+	}
+
+	for i, b := range backups {
+		if b.compressed {
+			continue
+		}
+		if err := compressfiles.GzipFile(b.path, b.path+".gz"); err != nil {
+			reportError("Error compressing rotated log file:", err)
+			continue
+		}
+		os.Remove(b.path)
+		backups[i] = backup{path: b.path + ".gz", time: b.time, compressed: true}
+	}
+
+	if f.opts.Rotation.MaxBackups > 0 && len(backups) > f.opts.Rotation.MaxBackups {
+		for _, b := range backups[:len(backups)-f.opts.Rotation.MaxBackups] {
+			if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+				reportError("Error removing old log file:", err)
+			}
+		}
+	}
+}
+
+// fileExists reports whether a file exists at path.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
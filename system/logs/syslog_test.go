@@ -0,0 +1,107 @@
+package logs
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testEntry = Entry{
+	Time:    time.Date(2024, 1, 2, 15, 4, 5, 123456789, time.UTC),
+	Level:   LevelWarn,
+	Message: "disk almost full",
+	Fields:  []Field{{Key: "mount", Value: "/var"}, {Key: "note", Value: `say "hi" [x]`}},
+}
+
+// wantSyslogMessage is testEntry formatted by a sink with AppName "app" and Hostname "host".
+var wantSyslogMessage = "<12>1 2024-01-02T15:04:05.123456Z host app " + strconv.Itoa(os.Getpid()) +
+	` - [lcme@32473 mount="/var" note="say \"hi\" [x\]"] disk almost full`
+
+func TestSyslogSinkDatagram(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	listener, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	sink, err := NewSyslogSink(SyslogOptions{Network: "unixgram", Address: path, AppName: "app", Hostname: "host"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	if err := sink.WriteEntry(testEntry); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 4096)
+	listener.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := listener.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf[:n]); got != wantSyslogMessage {
+		t.Errorf("message = %q, want %q", got, wantSyslogMessage)
+	}
+}
+
+func TestSyslogSinkStreamFraming(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	sink, err := NewSyslogSink(SyslogOptions{Network: "unix", Address: path, AppName: "app", Hostname: "host"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for i := 0; i < 2; i++ {
+		if err := sink.WriteEntry(testEntry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Octet counting (RFC 6587): "LENGTH SP MESSAGE", with messages back to back.
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(conn)
+	for i := 0; i < 2; i++ {
+		length, err := reader.ReadString(' ')
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+		if err != nil {
+			t.Fatalf("invalid frame length %q", length)
+		}
+		msg := make([]byte, n)
+		if _, err := io.ReadFull(reader, msg); err != nil {
+			t.Fatal(err)
+		}
+		if string(msg) != wantSyslogMessage {
+			t.Errorf("message %d = %q, want %q", i, msg, wantSyslogMessage)
+		}
+	}
+}
+
+func TestSyslogTimestampPrecision(t *testing.T) {
+	sink := &SyslogSink{opts: SyslogOptions{Network: "unixgram", Facility: 1, AppName: "app", Hostname: "host"}}
+	msg := string(sink.format(Entry{Time: time.Date(2024, 1, 2, 15, 4, 5, 1, time.FixedZone("", -3*3600))}))
+	if want := " 2024-01-02T15:04:05.000000-03:00 "; !strings.Contains(msg, want) {
+		t.Errorf("message %q does not contain the timestamp %q", msg, want)
+	}
+}
@@ -0,0 +1,250 @@
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	tailChunkSize       = 64 * 1024
+	defaultPollInterval = 250 * time.Millisecond
+)
+
+// Tail returns the last n lines of the file at path, oldest first, without the trailing newlines.
+// A last line without a newline is included. The file is read backwards, so only the end of a large log is loaded.
+func Tail(path string, n int) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines, _, err := tailFile(file, n, true)
+	return lines, err
+}
+
+// tailFile returns the last n lines of file and the offset right after the last complete line.
+// A last line without a newline is only returned with partial set; Follow leaves it to the reader
+// that follows the file, since it is still being written.
+func tailFile(file *os.File, n int, partial bool) ([]string, int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Read backwards until there are more than n newlines or the start of the file is reached.
+	var data []byte
+	offset, newlines := info.Size(), 0
+	for offset > 0 && newlines <= n {
+		chunk := min(int64(tailChunkSize), offset)
+		offset -= chunk
+		buf := make([]byte, chunk)
+		if _, err := file.ReadAt(buf, offset); err != nil && err != io.EOF {
+			return nil, 0, err
+		}
+		newlines += bytes.Count(buf, []byte{'\n'})
+		data = append(buf, data...)
+	}
+
+	end := offset + int64(bytes.LastIndexByte(data, '\n')+1)
+	if !partial {
+		data = data[:end-offset]
+	}
+	if n <= 0 || len(data) == 0 {
+		return nil, end, nil
+	}
+
+	lines := strings.Split(string(bytes.TrimSuffix(data, []byte{'\n'})), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, end, nil
+}
+
+// FollowOptions configures Follow.
+type FollowOptions struct {
+	Lines        int           // Number of existing lines to send before following. Zero starts at the end of the file.
+	PollInterval time.Duration // How often the file is checked for new lines. Defaults to 250ms.
+}
+
+// Follow sends the lines appended to the file at path, like tail -F.
+// It keeps working when the file is rotated (the path points to a new inode) or truncated:
+// the rest of the old file is read and the new file is followed from its start. While the path
+// is missing, the old file is still read and the path is checked again on every poll.
+// The file is read on every poll even when the receiver is slow, so rotations are not missed
+// while it catches up; only files created and rotated away between two polls are skipped.
+// Both channels are closed when ctx is done. Errors do not stop the follower.
+func Follow(ctx context.Context, path string, opts FollowOptions) (<-chan string, <-chan error) {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+	lines := make(chan string)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(lines)
+		defer close(errs)
+
+		f := &follower{path: path, errs: errs}
+		defer f.close()
+
+		// Only a file that exists when Follow starts is tailed; one created later is read from its start.
+		f.open(opts.Lines, true)
+		f.poll()
+
+		ticker := time.NewTicker(opts.PollInterval)
+		defer ticker.Stop()
+		for {
+			var out chan<- string
+			var next string
+			if len(f.pending) > 0 {
+				out, next = lines, f.pending[0]
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- next:
+				f.pending = f.pending[1:]
+			case <-ticker.C:
+				f.poll()
+			}
+		}
+	}()
+	return lines, errs
+}
+
+// follower holds the state of a Follow goroutine.
+type follower struct {
+	path    string
+	errs    chan<- error
+	file    *os.File
+	info    os.FileInfo
+	reader  *bufio.Reader
+	offset  int64
+	partial string
+	pending []string // Lines read but not yet received.
+}
+
+// poll reads the lines appended since the previous poll and switches to a file that replaced
+// the followed one.
+func (f *follower) poll() {
+	if f.file == nil {
+		f.open(0, false)
+	}
+	if f.file != nil {
+		f.readLines()
+		f.checkReplaced()
+	}
+}
+
+// open opens the followed file. On the first open it starts at the end, after queuing the last n lines;
+// files that replace a rotated one are read from the beginning.
+func (f *follower) open(n int, first bool) error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			f.report(err)
+		}
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		f.report(err)
+		return err
+	}
+
+	var offset int64
+	if first {
+		lines, end, err := tailFile(file, n, false)
+		if err != nil {
+			file.Close()
+			f.report(err)
+			return err
+		}
+		f.pending = append(f.pending, lines...)
+		offset = end
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		f.report(err)
+		return err
+	}
+
+	f.file, f.info, f.offset, f.partial = file, info, offset, ""
+	f.reader = bufio.NewReader(file)
+	return nil
+}
+
+// readLines queues every complete line available in the open file.
+func (f *follower) readLines() {
+	for {
+		chunk, err := f.reader.ReadString('\n')
+		f.offset += int64(len(chunk))
+		if err != nil {
+			// Keep the incomplete line until the writer finishes it.
+			f.partial += chunk
+			if err != io.EOF {
+				f.report(err)
+			}
+			return
+		}
+		f.pending = append(f.pending, strings.TrimSuffix(f.partial+chunk, "\n"))
+		f.partial = ""
+	}
+}
+
+// checkReplaced detects rotation and truncation of the followed file.
+func (f *follower) checkReplaced() {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		// Rotated and not recreated yet: keep the old file until the path reappears.
+		if !os.IsNotExist(err) {
+			f.report(err)
+		}
+		return
+	}
+	if !os.SameFile(info, f.info) {
+		// Rotated: read what was written to the old file before the switch, up to its end.
+		f.readLines()
+		if f.partial != "" {
+			f.pending = append(f.pending, f.partial)
+		}
+		f.close()
+		if f.open(0, false) == nil {
+			f.readLines()
+		}
+		return
+	}
+	if info.Size() < f.offset {
+		// Truncated, as by logrotate's copytruncate: start again from the beginning.
+		if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+			f.report(err)
+			return
+		}
+		f.reader.Reset(f.file)
+		f.offset, f.partial = 0, ""
+		f.readLines()
+	}
+}
+
+// close closes the followed file, if any.
+func (f *follower) close() {
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+}
+
+// report delivers an error without blocking; errors are dropped while the previous one is unread.
+func (f *follower) report(err error) {
+	select {
+	case f.errs <- err:
+	default:
+	}
+}
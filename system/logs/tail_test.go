@@ -0,0 +1,194 @@
+package logs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTail(t *testing.T) {
+	tests := []struct {
+		content string
+		n       int
+		want    []string
+	}{
+		{"one\ntwo\nthree\n", 2, []string{"two", "three"}},
+		{"one\ntwo\nthree", 2, []string{"two", "three"}},
+		{"one\ntwo\nthree", 5, []string{"one", "two", "three"}},
+		{"only", 1, []string{"only"}},
+		{"\n", 1, []string{""}},
+		{"", 3, nil},
+		{"one\n", 0, nil},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "app.log")
+		if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := Tail(path, tt.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tail(%q, %d) = %q, want %q", tt.content, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestTailLargeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	line := strings.Repeat("x", 1000)
+	content := strings.Repeat(line+"\n", 200) + "last"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Tail(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{line, line, "last"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tail returned %d lines ending in %q", len(got), got[len(got)-1])
+	}
+}
+
+// collect reads lines from Follow until want lines arrived or the timeout expires.
+func collect(t *testing.T, lines <-chan string, want int, timeout time.Duration) []string {
+	t.Helper()
+	var got []string
+	deadline := time.After(timeout)
+	for len(got) < want {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return got
+			}
+			got = append(got, line)
+		case <-deadline:
+			return got
+		}
+	}
+	return got
+}
+
+func appendLine(t *testing.T, path, line string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(line + "\n"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// followTest follows path, which must end with a complete line, and returns once that line
+// has been received, so that the follower has the file open.
+func followTest(t *testing.T, path string, poll time.Duration) <-chan string {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	lines, _ := Follow(ctx, path, FollowOptions{Lines: 1, PollInterval: poll})
+	if got := collect(t, lines, 1, 2*time.Second); len(got) != 1 {
+		t.Fatal("the last line of the file was not sent")
+	}
+	return lines
+}
+
+func TestFollowRenameAndRecreate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendLine(t, path, "old")
+	lines := followTest(t, path, 20*time.Millisecond)
+
+	// The lines are only received at the end, so the follower must not wait for the receiver.
+	var want []string
+	for i := 0; i < 5; i++ {
+		time.Sleep(100 * time.Millisecond)
+		if err := os.Rename(path, fmt.Sprintf("%s.%d", path, i)); err != nil {
+			t.Fatal(err)
+		}
+		// The path stays missing for a few polls before the next write creates it again.
+		time.Sleep(50 * time.Millisecond)
+		line := fmt.Sprintf("line-%d", i)
+		want = append(want, line)
+		appendLine(t, path, line)
+	}
+	if got := collect(t, lines, len(want), 2*time.Second); !reflect.DeepEqual(got, want) {
+		t.Errorf("followed %q, want %q", got, want)
+	}
+}
+
+func TestFollowFileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	file, err := OpenFileWith(path, FileOptions{Rotation: Rotation{MaxSize: 30, MaxBackups: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.Write([]byte("old\n")); err != nil {
+		t.Fatal(err)
+	}
+	lines := followTest(t, path, 20*time.Millisecond)
+
+	var want []string
+	for c := 'a'; c < 'a'+10; c++ {
+		time.Sleep(30 * time.Millisecond)
+		line := fmt.Sprintf("line-%c %s", c, strings.Repeat(string(c), 20))
+		want = append(want, line)
+		if _, err := file.Write([]byte(line + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := collect(t, lines, len(want), 2*time.Second); !reflect.DeepEqual(got, want) {
+		t.Errorf("followed %q, want %q", got, want)
+	}
+}
+
+func TestFollowCopyTruncate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendLine(t, path, "old line that makes the file longer")
+	lines := followTest(t, path, 20*time.Millisecond)
+
+	appendLine(t, path, "before")
+	time.Sleep(100 * time.Millisecond)
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	appendLine(t, path, "after")
+
+	want := []string{"before", "after"}
+	if got := collect(t, lines, len(want), 2*time.Second); !reflect.DeepEqual(got, want) {
+		t.Errorf("followed %q, want %q", got, want)
+	}
+}
+
+func TestFollowSeveralRotationsBetweenPolls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendLine(t, path, "old")
+	lines := followTest(t, path, 300*time.Millisecond)
+
+	// Several rotations within one poll interval: the rest of the followed file and the newest
+	// file are read, the files created and rotated in between are skipped.
+	appendLine(t, path, "rest of the old file")
+	for i := 0; i < 3; i++ {
+		if err := os.Rename(path, fmt.Sprintf("%s.%d", path, i)); err != nil {
+			t.Fatal(err)
+		}
+		appendLine(t, path, fmt.Sprintf("file-%d", i))
+	}
+	want := []string{"rest of the old file", "file-2"}
+	if got := collect(t, lines, len(want), 2*time.Second); !reflect.DeepEqual(got, want) {
+		t.Errorf("followed %q, want %q", got, want)
+	}
+
+	appendLine(t, path, "last")
+	if got := collect(t, lines, 1, 2*time.Second); !reflect.DeepEqual(got, []string{"last"}) {
+		t.Errorf("followed %q after the rotations, want the newest file", got)
+	}
+}
@@ -0,0 +1,204 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MemoryInfo contains every field of /proc/meminfo. Sizes are in bytes; the HugePages
+// counts are numbers of pages. Fields the running kernel does not report are zero.
+type MemoryInfo struct {
+	Total             uint64
+	Free              uint64
+	Available         uint64 // Estimate of the memory available for new applications without swapping.
+	Buffers           uint64
+	Cached            uint64 // Page cache, without SwapCached.
+	SwapCached        uint64
+	Active            uint64
+	Inactive          uint64
+	ActiveAnon        uint64
+	InactiveAnon      uint64
+	ActiveFile        uint64
+	InactiveFile      uint64
+	Unevictable       uint64
+	Mlocked           uint64
+	SwapTotal         uint64
+	SwapFree          uint64
+	Zswap             uint64
+	Zswapped          uint64
+	Dirty             uint64 // Waiting to be written back to disk.
+	Writeback         uint64 // Being written back to disk.
+	AnonPages         uint64
+	Mapped            uint64
+	Shmem             uint64 // Shared memory and tmpfs.
+	KReclaimable      uint64
+	Slab              uint64
+	SReclaimable      uint64
+	SUnreclaim        uint64
+	KernelStack       uint64
+	PageTables        uint64
+	SecPageTables     uint64
+	NFSUnstable       uint64
+	Bounce            uint64
+	WritebackTmp      uint64
+	CommitLimit       uint64
+	CommittedAS       uint64 // Memory allocated by processes, even if not used yet.
+	VmallocTotal      uint64
+	VmallocUsed       uint64
+	VmallocChunk      uint64
+	Percpu            uint64
+	HardwareCorrupted uint64
+	AnonHugePages     uint64
+	ShmemHugePages    uint64
+	ShmemPmdMapped    uint64
+	FileHugePages     uint64
+	FilePmdMapped     uint64
+	HugePagesTotal    uint64 // Pages.
+	HugePagesFree     uint64 // Pages.
+	HugePagesRsvd     uint64 // Pages.
+	HugePagesSurp     uint64 // Pages.
+	HugePageSize      uint64
+	Hugetlb           uint64
+	DirectMap4k       uint64
+	DirectMap2M       uint64
+	DirectMap1G       uint64
+
+	// Derived values, computed like the free command.
+	BuffCache   uint64  // Buffers + Cached + SReclaimable, the "buff/cache" column.
+	Used        uint64  // Total - Available, the "used" column of procps-ng 4 and later.
+	UsedNoCache uint64  // Total - Free - BuffCache, the "used" column of older procps versions.
+	UsedPercent float64 // Used as a percentage of Total.
+	SwapUsed    uint64  // SwapTotal - SwapFree.
+
+	// Fields holds every field of /proc/meminfo by its original name, including those not listed above.
+	// Values with a kB unit are converted to bytes.
+	Fields map[string]uint64
+}
+
+// GetMemoryInfo reads the full memory breakdown from /proc/meminfo.
+func GetMemoryInfo() (MemoryInfo, error) {
+	return readMemoryInfo("/proc/meminfo")
+}
+
+// readMemoryInfo parses a file in the format of /proc/meminfo.
+func readMemoryInfo(path string) (MemoryInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return MemoryInfo{}, err
+	}
+	defer file.Close()
+
+	info := MemoryInfo{Fields: make(map[string]uint64)}
+	targets := info.fieldTargets()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// Lines look like "MemTotal:       16318412 kB" or "HugePages_Total:       0".
+		name, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return MemoryInfo{}, fmt.Errorf("invalid /proc/meminfo value for %s: %v", name, err)
+		}
+		if len(fields) > 1 && fields[1] == "kB" {
+			value *= 1024
+		}
+		info.Fields[name] = value
+		if target, ok := targets[name]; ok {
+			*target = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return MemoryInfo{}, fmt.Errorf("error reading /proc/meminfo: %v", err)
+	}
+
+	// Kernels older than 3.14 do not report MemAvailable.
+	if _, ok := info.Fields["MemAvailable"]; !ok {
+		info.Available = info.Free + info.Buffers + info.Cached
+	}
+	info.BuffCache = info.Buffers + info.Cached + info.SReclaimable
+	if info.Available <= info.Total {
+		info.Used = info.Total - info.Available
+	}
+	if info.Free+info.BuffCache <= info.Total {
+		info.UsedNoCache = info.Total - info.Free - info.BuffCache
+	} else if info.Free <= info.Total {
+		// Containers with a virtualized meminfo may report caches larger than the limit.
+		info.UsedNoCache = info.Total - info.Free
+	}
+	if info.Total > 0 {
+		info.UsedPercent = 100 * float64(info.Used) / float64(info.Total)
+	}
+	if info.SwapFree <= info.SwapTotal {
+		info.SwapUsed = info.SwapTotal - info.SwapFree
+	}
+	return info, nil
+}
+
+// fieldTargets maps the /proc/meminfo names to the fields of m.
+func (m *MemoryInfo) fieldTargets() map[string]*uint64 {
+	return map[string]*uint64{
+		"MemTotal":          &m.Total,
+		"MemFree":           &m.Free,
+		"MemAvailable":      &m.Available,
+		"Buffers":           &m.Buffers,
+		"Cached":            &m.Cached,
+		"SwapCached":        &m.SwapCached,
+		"Active":            &m.Active,
+		"Inactive":          &m.Inactive,
+		"Active(anon)":      &m.ActiveAnon,
+		"Inactive(anon)":    &m.InactiveAnon,
+		"Active(file)":      &m.ActiveFile,
+		"Inactive(file)":    &m.InactiveFile,
+		"Unevictable":       &m.Unevictable,
+		"Mlocked":           &m.Mlocked,
+		"SwapTotal":         &m.SwapTotal,
+		"SwapFree":          &m.SwapFree,
+		"Zswap":             &m.Zswap,
+		"Zswapped":          &m.Zswapped,
+		"Dirty":             &m.Dirty,
+		"Writeback":         &m.Writeback,
+		"AnonPages":         &m.AnonPages,
+		"Mapped":            &m.Mapped,
+		"Shmem":             &m.Shmem,
+		"KReclaimable":      &m.KReclaimable,
+		"Slab":              &m.Slab,
+		"SReclaimable":      &m.SReclaimable,
+		"SUnreclaim":        &m.SUnreclaim,
+		"KernelStack":       &m.KernelStack,
+		"PageTables":        &m.PageTables,
+		"SecPageTables":     &m.SecPageTables,
+		"NFS_Unstable":      &m.NFSUnstable,
+		"Bounce":            &m.Bounce,
+		"WritebackTmp":      &m.WritebackTmp,
+		"CommitLimit":       &m.CommitLimit,
+		"Committed_AS":      &m.CommittedAS,
+		"VmallocTotal":      &m.VmallocTotal,
+		"VmallocUsed":       &m.VmallocUsed,
+		"VmallocChunk":      &m.VmallocChunk,
+		"Percpu":            &m.Percpu,
+		"HardwareCorrupted": &m.HardwareCorrupted,
+		"AnonHugePages":     &m.AnonHugePages,
+		"ShmemHugePages":    &m.ShmemHugePages,
+		"ShmemPmdMapped":    &m.ShmemPmdMapped,
+		"FileHugePages":     &m.FileHugePages,
+		"FilePmdMapped":     &m.FilePmdMapped,
+		"HugePages_Total":   &m.HugePagesTotal,
+		"HugePages_Free":    &m.HugePagesFree,
+		"HugePages_Rsvd":    &m.HugePagesRsvd,
+		"HugePages_Surp":    &m.HugePagesSurp,
+		"Hugepagesize":      &m.HugePageSize,
+		"Hugetlb":           &m.Hugetlb,
+		"DirectMap4k":       &m.DirectMap4k,
+		"DirectMap2M":       &m.DirectMap2M,
+		"DirectMap1G":       &m.DirectMap1G,
+	}
+}
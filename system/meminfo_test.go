@@ -0,0 +1,113 @@
+package system
+
+import "testing"
+
+// meminfoFixture is /proc/meminfo captured on a 6.8 kernel with 16 GB of RAM, trimmed.
+const meminfoFixture = `MemTotal:       16318412 kB
+MemFree:         1204784 kB
+MemAvailable:    9876540 kB
+Buffers:          412300 kB
+Cached:          8123456 kB
+SwapCached:        10240 kB
+Active:          6543210 kB
+Inactive:        5432100 kB
+SwapTotal:       4194300 kB
+SwapFree:        4100000 kB
+Zswap:                 0 kB
+Zswapped:              0 kB
+Dirty:              1536 kB
+Shmem:            654320 kB
+SReclaimable:     512000 kB
+Committed_AS:   18765432 kB
+VmallocTotal:   34359738367 kB
+HugePages_Total:       4
+HugePages_Free:        3
+HugePages_Rsvd:        0
+HugePages_Surp:        0
+Hugepagesize:       2048 kB
+Unaccepted:            0 kB
+`
+
+func TestReadMemoryInfo(t *testing.T) {
+	info, err := readMemoryInfo(writeProcFixture(t, "meminfo", meminfoFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const kB = 1024
+	checks := []struct {
+		name      string
+		got, want uint64
+	}{
+		{"Total", info.Total, 16318412 * kB},
+		{"Free", info.Free, 1204784 * kB},
+		{"Available", info.Available, 9876540 * kB},
+		{"Cached", info.Cached, 8123456 * kB},
+		{"SwapCached", info.SwapCached, 10240 * kB},
+		{"Dirty", info.Dirty, 1536 * kB},
+		{"CommittedAS", info.CommittedAS, 18765432 * kB},
+		{"VmallocTotal", info.VmallocTotal, 34359738367 * kB},
+		// HugePages counts have no unit and are not converted.
+		{"HugePagesTotal", info.HugePagesTotal, 4},
+		{"HugePagesFree", info.HugePagesFree, 3},
+		{"HugePageSize", info.HugePageSize, 2048 * kB},
+		{"BuffCache", info.BuffCache, (412300 + 8123456 + 512000) * kB},
+		{"Used", info.Used, (16318412 - 9876540) * kB},
+		{"UsedNoCache", info.UsedNoCache, (16318412 - 1204784 - 412300 - 8123456 - 512000) * kB},
+		{"SwapUsed", info.SwapUsed, (4194300 - 4100000) * kB},
+		// Not in the fixture.
+		{"Mlocked", info.Mlocked, 0},
+		{"DirectMap1G", info.DirectMap1G, 0},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %d, want %d", c.name, c.got, c.want)
+		}
+	}
+	if want := 100 * float64(16318412-9876540) / 16318412; info.UsedPercent != want {
+		t.Errorf("UsedPercent = %f, want %f", info.UsedPercent, want)
+	}
+
+	// Fields keeps every line by its original name, including those without a struct field.
+	if len(info.Fields) != 23 {
+		t.Errorf("len(Fields) = %d, want 23", len(info.Fields))
+	}
+	if v, ok := info.Fields["Unaccepted"]; !ok || v != 0 {
+		t.Errorf("Fields[Unaccepted] = %d, %v; want 0, true", v, ok)
+	}
+	if v := info.Fields["Committed_AS"]; v != 18765432*kB {
+		t.Errorf("Fields[Committed_AS] = %d, want %d", v, 18765432*kB)
+	}
+	if _, ok := info.Fields["Mlocked"]; ok {
+		t.Error("Fields has Mlocked, which is not in the file")
+	}
+}
+
+func TestReadMemoryInfoWithoutMemAvailable(t *testing.T) {
+	// Kernels older than 3.14 have no MemAvailable line.
+	info, err := readMemoryInfo(writeProcFixture(t, "meminfo", ""+
+		"MemTotal:        2048000 kB\n"+
+		"MemFree:          512000 kB\n"+
+		"Buffers:          128000 kB\n"+
+		"Cached:           256000 kB\n"+
+		"SwapTotal:             0 kB\n"+
+		"SwapFree:              0 kB\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := uint64(512000+128000+256000) * 1024; info.Available != want {
+		t.Errorf("Available = %d, want %d", info.Available, want)
+	}
+	if want := uint64(2048000-512000-128000-256000) * 1024; info.Used != want {
+		t.Errorf("Used = %d, want %d", info.Used, want)
+	}
+	if info.SwapUsed != 0 || info.SReclaimable != 0 {
+		t.Errorf("SwapUsed = %d, SReclaimable = %d; want 0", info.SwapUsed, info.SReclaimable)
+	}
+}
+
+func TestReadMemoryInfoInvalidValue(t *testing.T) {
+	_, err := readMemoryInfo(writeProcFixture(t, "meminfo", "MemTotal:       16318412 kB\nMemFree:        lots kB\n"))
+	if err == nil {
+		t.Error("readMemoryInfo succeeded with an invalid value")
+	}
+}
@@ -0,0 +1,193 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Mount describes a mounted file system from /proc/self/mountinfo, with its usage from statfs.
+// Sizes are in bytes. The usage fields are zero when the file system could not be queried,
+// e.g. because the mount point is not accessible.
+type Mount struct {
+	ID           int
+	ParentID     int
+	MajorMinor   string // Device number, e.g. "8:1".
+	Device       string // Mount source, e.g. "/dev/sda1" or "tmpfs".
+	Root         string // Path inside the file system that is mounted, "/" unless it is a bind mount.
+	MountPoint   string
+	FSType       string
+	Options      []string // Per-mount options, e.g. "rw", "noatime".
+	SuperOptions []string // File system options, e.g. "errors=remount-ro".
+	ReadOnly     bool
+
+	BlockSize   uint64
+	Total       uint64
+	Used        uint64
+	Free        uint64  // Free space, including the blocks reserved for root.
+	Available   uint64  // Free space for unprivileged users.
+	Reserved    uint64  // Free - Available.
+	UsedPercent float64 // Used as a percentage of Used + Available, like df.
+	Inodes      uint64
+	InodesUsed  uint64
+	InodesFree  uint64
+}
+
+// pseudoFSTypes are the file systems excluded by MountFilter.ExcludePseudo.
+// They are kernel interfaces or memory-backed, not storage.
+var pseudoFSTypes = map[string]bool{
+	"autofs": true, "binfmt_misc": true, "bpf": true, "cgroup": true, "cgroup2": true,
+	"configfs": true, "debugfs": true, "devpts": true, "devtmpfs": true, "efivarfs": true,
+	"fusectl": true, "hugetlbfs": true, "mqueue": true, "nsfs": true, "overlay": true,
+	"proc": true, "pstore": true, "ramfs": true, "rpc_pipefs": true, "securityfs": true,
+	"selinuxfs": true, "squashfs": true, "sysfs": true, "tmpfs": true, "tracefs": true,
+}
+
+// MountFilter selects the mounts returned by GetMountsWith.
+type MountFilter struct {
+	ExcludePseudo  bool     // Exclude pseudo and memory file systems such as proc, sysfs, tmpfs and overlay.
+	ExcludeFSTypes []string // File system types to exclude.
+	FSTypes        []string // When set, only these file system types are returned.
+	SkipUsage      bool     // Do not call statfs, e.g. to avoid blocking on an unreachable NFS server.
+}
+
+// GetMounts returns every mounted file system with its block and inode usage.
+func GetMounts() ([]Mount, error) {
+	return GetMountsWith(MountFilter{})
+}
+
+// GetMountsWith returns the mounted file systems selected by filter.
+func GetMountsWith(filter MountFilter) ([]Mount, error) {
+	file, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var mounts []Mount
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		mount, err := parseMountInfoLine(scanner.Text())
+		if err != nil {
+			return nil, err
+		}
+		if !filter.match(mount.FSType) {
+			continue
+		}
+		if !filter.SkipUsage {
+			mount.readUsage()
+		}
+		mounts = append(mounts, mount)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading /proc/self/mountinfo: %v", err)
+	}
+	return mounts, nil
+}
+
+// match reports whether a file system type passes the filter.
+func (f MountFilter) match(fsType string) bool {
+	if f.ExcludePseudo && pseudoFSTypes[fsType] {
+		return false
+	}
+	for _, excluded := range f.ExcludeFSTypes {
+		if fsType == excluded {
+			return false
+		}
+	}
+	if len(f.FSTypes) == 0 {
+		return true
+	}
+	for _, included := range f.FSTypes {
+		if fsType == included {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMountInfoLine parses a line such as
+// "36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue".
+// The optional fields before "-" vary in number.
+func parseMountInfoLine(line string) (Mount, error) {
+	fields := strings.Fields(line)
+	separator := -1
+	for i := 6; i < len(fields); i++ {
+		if fields[i] == "-" {
+			separator = i
+			break
+		}
+	}
+	if separator < 0 || len(fields) < separator+3 {
+		return Mount{}, fmt.Errorf("invalid mountinfo line: %q", line)
+	}
+
+	var mount Mount
+	var err error
+	if mount.ID, err = strconv.Atoi(fields[0]); err != nil {
+		return Mount{}, fmt.Errorf("invalid mountinfo line: %q", line)
+	}
+	if mount.ParentID, err = strconv.Atoi(fields[1]); err != nil {
+		return Mount{}, fmt.Errorf("invalid mountinfo line: %q", line)
+	}
+	mount.MajorMinor = fields[2]
+	mount.Root = unescapeMountField(fields[3])
+	mount.MountPoint = unescapeMountField(fields[4])
+	mount.Options = strings.Split(fields[5], ",")
+	mount.FSType = fields[separator+1]
+	mount.Device = unescapeMountField(fields[separator+2])
+	if len(fields) > separator+3 {
+		mount.SuperOptions = strings.Split(fields[separator+3], ",")
+	}
+	mount.ReadOnly = containsString(mount.Options, "ro") || containsString(mount.SuperOptions, "ro")
+	return mount, nil
+}
+
+// unescapeMountField decodes the octal escapes the kernel uses for spaces, tabs,
+// newlines and backslashes in paths, e.g. "\040".
+func unescapeMountField(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// readUsage fills the block and inode usage of the mount with statfs.
+func (m *Mount) readUsage() {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(m.MountPoint, &stat); err != nil {
+		return
+	}
+	blockSize := uint64(stat.Frsize)
+	if blockSize == 0 {
+		blockSize = uint64(stat.Bsize)
+	}
+	m.BlockSize = blockSize
+	m.Total = stat.Blocks * blockSize
+	m.Free = stat.Bfree * blockSize
+	m.Available = stat.Bavail * blockSize
+	m.Used = m.Total - m.Free
+	if m.Free > m.Available {
+		m.Reserved = m.Free - m.Available
+	}
+	if m.Used+m.Available > 0 {
+		m.UsedPercent = 100 * float64(m.Used) / float64(m.Used+m.Available)
+	}
+	m.Inodes = stat.Files
+	m.InodesFree = stat.Ffree
+	m.InodesUsed = stat.Files - stat.Ffree
+}
@@ -0,0 +1,105 @@
+package system
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMountInfoLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want Mount
+	}{
+		{"root file system",
+			"29 1 8:2 / / rw,relatime shared:1 - ext4 /dev/sda2 rw,errors=remount-ro",
+			Mount{ID: 29, ParentID: 1, MajorMinor: "8:2", Device: "/dev/sda2", Root: "/", MountPoint: "/", FSType: "ext4",
+				Options: []string{"rw", "relatime"}, SuperOptions: []string{"rw", "errors=remount-ro"}}},
+		{"no optional fields",
+			"36 35 98:0 /mnt1 /mnt2 rw,noatime - ext3 /dev/root rw,errors=continue",
+			Mount{ID: 36, ParentID: 35, MajorMinor: "98:0", Device: "/dev/root", Root: "/mnt1", MountPoint: "/mnt2", FSType: "ext3",
+				Options: []string{"rw", "noatime"}, SuperOptions: []string{"rw", "errors=continue"}}},
+		{"several optional fields",
+			"612 29 0:52 / /run/user/1000 rw,nosuid,nodev shared:342 master:12 propagate_from:3 unbindable - tmpfs tmpfs rw,size=1630276k,mode=700",
+			Mount{ID: 612, ParentID: 29, MajorMinor: "0:52", Device: "tmpfs", Root: "/", MountPoint: "/run/user/1000", FSType: "tmpfs",
+				Options: []string{"rw", "nosuid", "nodev"}, SuperOptions: []string{"rw", "size=1630276k", "mode=700"}}},
+		// A bind mount of a subdirectory has that directory as its root and the device of the source.
+		{"bind mount",
+			"845 29 8:2 /var/lib/docker/volumes/data /srv/data ro,relatime shared:1 - ext4 /dev/sda2 rw,errors=remount-ro",
+			Mount{ID: 845, ParentID: 29, MajorMinor: "8:2", Device: "/dev/sda2", Root: "/var/lib/docker/volumes/data", MountPoint: "/srv/data",
+				FSType: "ext4", Options: []string{"ro", "relatime"}, SuperOptions: []string{"rw", "errors=remount-ro"}, ReadOnly: true}},
+		{"octal escapes",
+			`901 29 0:60 /My\040Files /media/usb\040disk\011tab\134back rw,nosuid - vfat /dev/sdb1\040part ro,fmask=0022`,
+			Mount{ID: 901, ParentID: 29, MajorMinor: "0:60", Device: "/dev/sdb1 part", Root: "/My Files", MountPoint: "/media/usb disk\ttab\\back",
+				FSType: "vfat", Options: []string{"rw", "nosuid"}, SuperOptions: []string{"ro", "fmask=0022"}, ReadOnly: true}},
+		// Some file systems have no super options field at all.
+		{"no super options",
+			"40 29 0:35 / /proc/sys/fs/binfmt_misc rw,relatime - binfmt_misc binfmt_misc",
+			Mount{ID: 40, ParentID: 29, MajorMinor: "0:35", Device: "binfmt_misc", Root: "/", MountPoint: "/proc/sys/fs/binfmt_misc",
+				FSType: "binfmt_misc", Options: []string{"rw", "relatime"}}},
+	}
+	for _, tt := range tests {
+		got, err := parseMountInfoLine(tt.line)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseMountInfoLineInvalid(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"29 1 8:2 / / rw,relatime shared:1 ext4 /dev/sda2 rw",
+		"29 1 8:2 / / rw,relatime - ext4",
+		"x 1 8:2 / / rw - ext4 /dev/sda2 rw",
+		"29 y 8:2 / / rw - ext4 /dev/sda2 rw",
+	} {
+		if mount, err := parseMountInfoLine(line); err == nil {
+			t.Errorf("parseMountInfoLine(%q) = %+v, want an error", line, mount)
+		}
+	}
+}
+
+func TestUnescapeMountField(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"/plain/path", "/plain/path"},
+		{`/a\040b`, "/a b"},
+		{`\040start`, " start"},
+		{`end\040`, "end "},
+		{`/new\012line`, "/new\nline"},
+		// Not a valid escape: kept as is.
+		{`/a\09b`, `/a\09b`},
+		{`/short\04`, `/short\04`},
+		{`/trailing\`, `/trailing\`},
+	}
+	for _, tt := range tests {
+		if got := unescapeMountField(tt.in); got != tt.want {
+			t.Errorf("unescapeMountField(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMountFilterMatch(t *testing.T) {
+	tests := []struct {
+		filter MountFilter
+		fsType string
+		want   bool
+	}{
+		{MountFilter{}, "proc", true},
+		{MountFilter{ExcludePseudo: true}, "proc", false},
+		{MountFilter{ExcludePseudo: true}, "ext4", true},
+		{MountFilter{ExcludeFSTypes: []string{"nfs"}}, "nfs", false},
+		{MountFilter{FSTypes: []string{"ext4", "xfs"}}, "xfs", true},
+		{MountFilter{FSTypes: []string{"ext4", "xfs"}}, "btrfs", false},
+		{MountFilter{FSTypes: []string{"tmpfs"}, ExcludePseudo: true}, "tmpfs", false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.match(tt.fsType); got != tt.want {
+			t.Errorf("%+v.match(%q) = %v, want %v", tt.filter, tt.fsType, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package system
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// ARP flags in /proc/net/arp, from include/uapi/linux/if_arp.h.
+const (
+	arpFlagComplete  = 0x02
+	arpFlagPermanent = 0x04
+)
+
+// Neighbor is an entry of the IPv4 ARP table or of the IPv6 neighbor table:
+// a host on the same link and its hardware address.
+type Neighbor struct {
+	Family    string // "ipv4" or "ipv6".
+	IP        net.IP
+	MAC       string // Empty while the address is being resolved.
+	Interface string
+	State     string // "REACHABLE", "STALE", "DELAY", "PROBE", "FAILED", "INCOMPLETE", "NOARP", "PERMANENT" or "COMPLETE".
+}
+
+// neighborStates are the NUD states of the neighbor table, from include/uapi/linux/neighbour.h.
+var neighborStates = []struct {
+	flag uint16
+	name string
+}{
+	{unix.NUD_PERMANENT, "PERMANENT"},
+	{unix.NUD_NOARP, "NOARP"},
+	{unix.NUD_REACHABLE, "REACHABLE"},
+	{unix.NUD_STALE, "STALE"},
+	{unix.NUD_DELAY, "DELAY"},
+	{unix.NUD_PROBE, "PROBE"},
+	{unix.NUD_FAILED, "FAILED"},
+	{unix.NUD_INCOMPLETE, "INCOMPLETE"},
+}
+
+// GetNeighbors returns the IPv4 ARP table followed by the IPv6 neighbor table, which the kernel
+// only reports through rtnetlink. IPv6 neighbors are left out when rtnetlink is not available,
+// as in some sandboxes.
+func GetNeighbors() ([]Neighbor, error) {
+	neighbors, err := GetARPTable()
+	if err != nil {
+		return nil, err
+	}
+	ipv6Neighbors, err := GetIPv6Neighbors()
+	if err != nil {
+		return neighbors, nil
+	}
+	return append(neighbors, ipv6Neighbors...), nil
+}
+
+// GetARPTable returns the IPv4 neighbors, like ip -4 neigh, from rtnetlink. When rtnetlink is
+// not available it falls back to /proc/net/arp, which only tells permanent, complete and incomplete
+// entries apart: resolved entries are then reported as COMPLETE instead of their NUD state.
+func GetARPTable() ([]Neighbor, error) {
+	if neighbors, err := dumpNeighbors(unix.AF_INET); err == nil {
+		return neighbors, nil
+	}
+	return readARPTable("/proc/net/arp")
+}
+
+// readARPTable returns the IPv4 neighbors from an ARP table in the format of /proc/net/arp.
+func readARPTable(path string) ([]Neighbor, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var neighbors []Neighbor
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // Skip the header line
+	for scanner.Scan() {
+		// IP address  HW type  Flags  HW address  Mask  Device
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		flags, _ := strconv.ParseUint(fields[2], 0, 32)
+		neighbor := Neighbor{Family: "ipv4", IP: net.ParseIP(fields[0]), Interface: fields[5]}
+		switch {
+		case flags&arpFlagPermanent != 0:
+			neighbor.State = "PERMANENT"
+		case flags&arpFlagComplete != 0:
+			neighbor.State = "COMPLETE"
+		default:
+			neighbor.State = "INCOMPLETE"
+		}
+		if neighbor.State != "INCOMPLETE" {
+			neighbor.MAC = fields[3]
+		}
+		neighbors = append(neighbors, neighbor)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return neighbors, nil
+}
+
+// GetIPv6Neighbors returns the IPv6 neighbor table, like ip -6 neigh, by dumping it through rtnetlink.
+func GetIPv6Neighbors() ([]Neighbor, error) {
+	return dumpNeighbors(unix.AF_INET6)
+}
+
+// dumpNeighbors returns the neighbor table of one address family, AF_INET or AF_INET6, through rtnetlink.
+func dumpNeighbors(family int) ([]Neighbor, error) {
+	data, err := syscall.NetlinkRIB(unix.RTM_GETNEIGH, family)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the neighbor table: %v", err)
+	}
+	messages, err := syscall.ParseNetlinkMessage(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the neighbor table: %v", err)
+	}
+
+	familyName := "ipv4"
+	if family == unix.AF_INET6 {
+		familyName = "ipv6"
+	}
+	names := make(map[int32]string)
+	if ifaces, err := net.Interfaces(); err == nil {
+		for _, iface := range ifaces {
+			names[int32(iface.Index)] = iface.Name
+		}
+	}
+
+	var neighbors []Neighbor
+	for _, message := range messages {
+		if message.Header.Type != unix.RTM_NEWNEIGH || len(message.Data) < unix.SizeofNdMsg {
+			continue
+		}
+		header := (*unix.NdMsg)(unsafe.Pointer(&message.Data[0]))
+		if int(header.Family) != family {
+			continue
+		}
+		neighbor := Neighbor{
+			Family:    familyName,
+			Interface: names[header.Ifindex],
+			State:     neighborState(header.State),
+		}
+		for attrType, value := range parseNetlinkAttributes(message.Data[unix.SizeofNdMsg:]) {
+			switch attrType {
+			case unix.NDA_DST:
+				neighbor.IP = net.IP(value)
+			case unix.NDA_LLADDR:
+				neighbor.MAC = net.HardwareAddr(value).String()
+			}
+		}
+		if neighbor.IP != nil {
+			neighbors = append(neighbors, neighbor)
+		}
+	}
+	return neighbors, nil
+}
+
+// neighborState names a NUD state bit mask.
+func neighborState(state uint16) string {
+	for _, s := range neighborStates {
+		if state&s.flag != 0 {
+			return s.name
+		}
+	}
+	return "NONE"
+}
+
+// parseNetlinkAttributes splits the route attributes that follow a netlink message header.
+// Each attribute is a 2-byte length and a 2-byte type followed by the value, padded to 4 bytes.
+func parseNetlinkAttributes(data []byte) map[uint16][]byte {
+	attrs := make(map[uint16][]byte)
+	for len(data) >= unix.SizeofRtAttr {
+		length := int(binary.NativeEndian.Uint16(data[0:2]))
+		attrType := binary.NativeEndian.Uint16(data[2:4])
+		if length < unix.SizeofRtAttr || length > len(data) {
+			break
+		}
+		attrs[attrType] = data[unix.SizeofRtAttr:length]
+		aligned := (length + unix.NLMSG_ALIGNTO - 1) &^ (unix.NLMSG_ALIGNTO - 1)
+		if aligned > len(data) {
+			break
+		}
+		data = data[aligned:]
+	}
+	return attrs
+}
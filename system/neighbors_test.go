@@ -0,0 +1,54 @@
+package system
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestReadARPTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "arp")
+	content := "IP address       HW type     Flags       HW address            Mask     Device\n" +
+		"192.168.1.1      0x1         0x2         aa:bb:cc:dd:ee:01     *        eth0\n" +
+		"192.168.1.20     0x1         0x6         aa:bb:cc:dd:ee:14     *        eth0\n" +
+		"192.168.1.30     0x1         0x0         00:00:00:00:00:00     *        eth0\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readARPTable(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Neighbor{
+		{Family: "ipv4", IP: net.ParseIP("192.168.1.1"), MAC: "aa:bb:cc:dd:ee:01", Interface: "eth0", State: "COMPLETE"},
+		{Family: "ipv4", IP: net.ParseIP("192.168.1.20"), MAC: "aa:bb:cc:dd:ee:14", Interface: "eth0", State: "PERMANENT"},
+		{Family: "ipv4", IP: net.ParseIP("192.168.1.30"), Interface: "eth0", State: "INCOMPLETE"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ARP table = %+v, want %+v", got, want)
+	}
+}
+
+func TestGetARPTableReportsNUDStates(t *testing.T) {
+	neighbors, err := dumpNeighbors(unix.AF_INET)
+	if err != nil {
+		t.Skip("rtnetlink is not available:", err)
+	}
+	arp, err := GetARPTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(arp) != len(neighbors) {
+		t.Fatalf("GetARPTable returned %d neighbors, rtnetlink %d", len(arp), len(neighbors))
+	}
+	for _, n := range arp {
+		if n.Family != "ipv4" || n.State == "COMPLETE" {
+			t.Errorf("neighbor %+v was not read from rtnetlink", n)
+		}
+	}
+}
@@ -0,0 +1,460 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IPStats are the IP counters of /proc/net/snmp, or of /proc/net/snmp6 for IPv6.
+type IPStats struct {
+	InReceives      uint64
+	InHdrErrors     uint64
+	InAddrErrors    uint64
+	InUnknownProtos uint64
+	InDiscards      uint64
+	InDelivers      uint64
+	OutRequests     uint64
+	OutDiscards     uint64
+	OutNoRoutes     uint64
+	ForwDatagrams   uint64
+	ReasmFails      uint64
+	FragFails       uint64
+}
+
+// ICMPStats are the ICMP counters of /proc/net/snmp, or of /proc/net/snmp6 for ICMPv6.
+type ICMPStats struct {
+	InMsgs          uint64
+	InErrors        uint64
+	InDestUnreachs  uint64
+	OutMsgs         uint64
+	OutErrors       uint64
+	OutDestUnreachs uint64
+}
+
+// TCPStats are the TCP counters of /proc/net/snmp and /proc/net/netstat. They cover both
+// IPv4 and IPv6. CurrEstab is the only gauge: the number of connections now established.
+type TCPStats struct {
+	ActiveOpens       uint64 // Connections opened by connect().
+	PassiveOpens      uint64 // Connections accepted.
+	AttemptFails      uint64 // Connection attempts that failed.
+	EstabResets       uint64 // Established connections reset.
+	CurrEstab         uint64
+	InSegs            uint64
+	OutSegs           uint64
+	RetransSegs       uint64
+	InErrs            uint64
+	OutRsts           uint64 // Segments sent with the RST flag.
+	InCsumErrors      uint64
+	ListenOverflows   uint64 // Connections dropped because the accept queue was full.
+	ListenDrops       uint64 // Connections dropped by a listening socket, overflows included.
+	SyncookiesSent    uint64
+	Timeouts          uint64 // Retransmission timeouts.
+	FastRetrans       uint64
+	LostRetransmit    uint64
+	SynRetrans        uint64
+	AbortOnData       uint64
+	AbortOnTimeout    uint64
+	BacklogDrop       uint64
+	OFODrop           uint64 // Out-of-order segments dropped for lack of receive memory.
+	ReqQFullDrop      uint64
+	TimeWaitOverflow  uint64
+	ZeroWindowDrop    uint64
+	RcvQDrop          uint64
+	AbortOnMemory     uint64
+	MemoryPressures   uint64
+	RetransFail       uint64
+	SpuriousRTOs      uint64
+	DelayedACKs       uint64
+	DelayedACKLost    uint64
+	PAWSEstab         uint64
+	OutOfWindowIcmps  uint64
+	EmbryonicRsts     uint64
+	PruneCalled       uint64
+	RcvPruned         uint64
+	ChallengeACK      uint64
+	SACKReneging      uint64
+	SlowStartRetrans  uint64
+	LossProbes        uint64
+	DSACKRecv         uint64
+	WantZeroWindowAdv uint64
+}
+
+// UDPStats are the UDP counters of /proc/net/snmp, or of /proc/net/snmp6 for UDPv6.
+type UDPStats struct {
+	InDatagrams  uint64
+	NoPorts      uint64 // Datagrams received for a port without a socket.
+	InErrors     uint64 // Datagrams dropped on receive, buffer errors included.
+	OutDatagrams uint64
+	RcvbufErrors uint64 // Datagrams dropped because the socket receive buffer was full.
+	SndbufErrors uint64
+	InCsumErrors uint64
+	IgnoredMulti uint64
+	MemErrors    uint64
+}
+
+// NetworkProtocolStats are the protocol counters of the network stack, since boot, like netstat -s.
+type NetworkProtocolStats struct {
+	IP     IPStats
+	IPv6   IPStats
+	ICMP   ICMPStats
+	ICMPv6 ICMPStats
+	TCP    TCPStats
+	UDP    UDPStats
+	UDPv6  UDPStats
+
+	// Counters holds every value of the three files, keyed by section and name, e.g. "Tcp.RetransSegs",
+	// "TcpExt.ListenOverflows" or "Udp6.RcvbufErrors". Negative values, such as Tcp.MaxConn, are left out.
+	Counters map[string]uint64
+}
+
+// protocolGauges are the values of Counters that are settings or gauges rather than counters,
+// and have no rate.
+var protocolGauges = map[string]bool{
+	"Ip.Forwarding":    true,
+	"Ip.DefaultTTL":    true,
+	"Tcp.RtoAlgorithm": true,
+	"Tcp.RtoMin":       true,
+	"Tcp.RtoMax":       true,
+	"Tcp.CurrEstab":    true,
+}
+
+// snmp6Sections are the prefixes of the names in /proc/net/snmp6. UdpLite6 comes before Udp6
+// for the prefix match to be unambiguous.
+var snmp6Sections = []string{"Ip6", "Icmp6", "UdpLite6", "Udp6"}
+
+// GetNetworkProtocolStats reads the IP, ICMP, TCP and UDP counters from /proc/net/snmp,
+// /proc/net/snmp6 and /proc/net/netstat. The IPv6 counters are zero when IPv6 is disabled.
+func GetNetworkProtocolStats() (NetworkProtocolStats, error) {
+	counters := make(map[string]uint64)
+	if err := readSNMPTable("/proc/net/snmp", counters); err != nil {
+		return NetworkProtocolStats{}, err
+	}
+	if err := readSNMPTable("/proc/net/netstat", counters); err != nil {
+		return NetworkProtocolStats{}, err
+	}
+	if err := readSNMP6("/proc/net/snmp6", counters); err != nil && !os.IsNotExist(err) {
+		return NetworkProtocolStats{}, err
+	}
+	return newNetworkProtocolStats(counters), nil
+}
+
+// newNetworkProtocolStats fills the typed counters from a map read by readSNMPTable and readSNMP6.
+func newNetworkProtocolStats(c map[string]uint64) NetworkProtocolStats {
+	ip := func(section, forwarded string) IPStats {
+		return IPStats{
+			InReceives:      c[section+".InReceives"],
+			InHdrErrors:     c[section+".InHdrErrors"],
+			InAddrErrors:    c[section+".InAddrErrors"],
+			InUnknownProtos: c[section+".InUnknownProtos"],
+			InDiscards:      c[section+".InDiscards"],
+			InDelivers:      c[section+".InDelivers"],
+			OutRequests:     c[section+".OutRequests"],
+			OutDiscards:     c[section+".OutDiscards"],
+			OutNoRoutes:     c[section+".OutNoRoutes"],
+			ForwDatagrams:   c[section+"."+forwarded],
+			ReasmFails:      c[section+".ReasmFails"],
+			FragFails:       c[section+".FragFails"],
+		}
+	}
+	icmp := func(section string) ICMPStats {
+		return ICMPStats{
+			InMsgs:          c[section+".InMsgs"],
+			InErrors:        c[section+".InErrors"],
+			InDestUnreachs:  c[section+".InDestUnreachs"],
+			OutMsgs:         c[section+".OutMsgs"],
+			OutErrors:       c[section+".OutErrors"],
+			OutDestUnreachs: c[section+".OutDestUnreachs"],
+		}
+	}
+	udp := func(section string) UDPStats {
+		return UDPStats{
+			InDatagrams:  c[section+".InDatagrams"],
+			NoPorts:      c[section+".NoPorts"],
+			InErrors:     c[section+".InErrors"],
+			OutDatagrams: c[section+".OutDatagrams"],
+			RcvbufErrors: c[section+".RcvbufErrors"],
+			SndbufErrors: c[section+".SndbufErrors"],
+			InCsumErrors: c[section+".InCsumErrors"],
+			IgnoredMulti: c[section+".IgnoredMulti"],
+			MemErrors:    c[section+".MemErrors"],
+		}
+	}
+	return NetworkProtocolStats{
+		IP:     ip("Ip", "ForwDatagrams"),
+		IPv6:   ip("Ip6", "OutForwDatagrams"),
+		ICMP:   icmp("Icmp"),
+		ICMPv6: icmp("Icmp6"),
+		TCP: TCPStats{
+			ActiveOpens:       c["Tcp.ActiveOpens"],
+			PassiveOpens:      c["Tcp.PassiveOpens"],
+			AttemptFails:      c["Tcp.AttemptFails"],
+			EstabResets:       c["Tcp.EstabResets"],
+			CurrEstab:         c["Tcp.CurrEstab"],
+			InSegs:            c["Tcp.InSegs"],
+			OutSegs:           c["Tcp.OutSegs"],
+			RetransSegs:       c["Tcp.RetransSegs"],
+			InErrs:            c["Tcp.InErrs"],
+			OutRsts:           c["Tcp.OutRsts"],
+			InCsumErrors:      c["Tcp.InCsumErrors"],
+			ListenOverflows:   c["TcpExt.ListenOverflows"],
+			ListenDrops:       c["TcpExt.ListenDrops"],
+			SyncookiesSent:    c["TcpExt.SyncookiesSent"],
+			Timeouts:          c["TcpExt.TCPTimeouts"],
+			FastRetrans:       c["TcpExt.TCPFastRetrans"],
+			LostRetransmit:    c["TcpExt.TCPLostRetransmit"],
+			SynRetrans:        c["TcpExt.TCPSynRetrans"],
+			AbortOnData:       c["TcpExt.TCPAbortOnData"],
+			AbortOnTimeout:    c["TcpExt.TCPAbortOnTimeout"],
+			BacklogDrop:       c["TcpExt.TCPBacklogDrop"],
+			OFODrop:           c["TcpExt.TCPOFODrop"],
+			ReqQFullDrop:      c["TcpExt.TCPReqQFullDrop"],
+			TimeWaitOverflow:  c["TcpExt.TCPTimeWaitOverflow"],
+			ZeroWindowDrop:    c["TcpExt.TCPZeroWindowDrop"],
+			RcvQDrop:          c["TcpExt.TCPRcvQDrop"],
+			AbortOnMemory:     c["TcpExt.TCPAbortOnMemory"],
+			MemoryPressures:   c["TcpExt.TCPMemoryPressures"],
+			RetransFail:       c["TcpExt.TCPRetransFail"],
+			SpuriousRTOs:      c["TcpExt.TCPSpuriousRTOs"],
+			DelayedACKs:       c["TcpExt.DelayedACKs"],
+			DelayedACKLost:    c["TcpExt.DelayedACKLost"],
+			PAWSEstab:         c["TcpExt.PAWSEstab"],
+			OutOfWindowIcmps:  c["TcpExt.OutOfWindowIcmps"],
+			EmbryonicRsts:     c["TcpExt.EmbryonicRsts"],
+			PruneCalled:       c["TcpExt.PruneCalled"],
+			RcvPruned:         c["TcpExt.RcvPruned"],
+			ChallengeACK:      c["TcpExt.TCPChallengeACK"],
+			SACKReneging:      c["TcpExt.TCPSACKReneging"],
+			SlowStartRetrans:  c["TcpExt.TCPSlowStartRetrans"],
+			LossProbes:        c["TcpExt.TCPLossProbes"],
+			DSACKRecv:         c["TcpExt.TCPDSACKRecv"],
+			WantZeroWindowAdv: c["TcpExt.TCPWantZeroWindowAdv"],
+		},
+		UDP:      udp("Udp"),
+		UDPv6:    udp("Udp6"),
+		Counters: c,
+	}
+}
+
+// readSNMPTable parses /proc/net/snmp or /proc/net/netstat, where each section is a line of
+// names followed by a line of values with the same prefix:
+//
+//	Tcp: RtoAlgorithm RtoMin RtoMax MaxConn ActiveOpens ...
+//	Tcp: 1 200 120000 -1 11 ...
+func readSNMPTable(path string, counters map[string]uint64) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024) // The TcpExt lines are long.
+	for scanner.Scan() {
+		section, names, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || !scanner.Scan() {
+			continue
+		}
+		valueSection, values, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || valueSection != section {
+			return fmt.Errorf("invalid %s section %q", path, section)
+		}
+		nameFields := strings.Fields(names)
+		valueFields := strings.Fields(values)
+		for i := 0; i < len(nameFields) && i < len(valueFields); i++ {
+			if value, err := strconv.ParseUint(valueFields[i], 10, 64); err == nil {
+				counters[section+"."+nameFields[i]] = value
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return nil
+}
+
+// readSNMP6 parses /proc/net/snmp6, where each line is a name and a value, e.g. "Ip6InReceives 3".
+// Names are stored with a dot after the section, as "Ip6.InReceives", like readSNMPTable.
+func readSNMP6(path string, counters map[string]uint64) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		name := fields[0]
+		for _, section := range snmp6Sections {
+			if strings.HasPrefix(name, section) {
+				name = section + "." + strings.TrimPrefix(name, section)
+				break
+			}
+		}
+		counters[name] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return nil
+}
+
+// TCPRates are the main TCP counters between two samples, per second.
+type TCPRates struct {
+	ActiveOpensPerSec     float64
+	PassiveOpensPerSec    float64
+	AttemptFailsPerSec    float64
+	EstabResetsPerSec     float64
+	InSegsPerSec          float64
+	OutSegsPerSec         float64
+	RetransSegsPerSec     float64
+	OutRstsPerSec         float64
+	InErrsPerSec          float64
+	ListenOverflowsPerSec float64
+	ListenDropsPerSec     float64
+	TimeoutsPerSec        float64
+	RetransPercent        float64 // Retransmitted segments as a percentage of the segments sent.
+}
+
+// UDPRates are the main UDP counters between two samples, per second.
+type UDPRates struct {
+	InDatagramsPerSec  float64
+	OutDatagramsPerSec float64
+	NoPortsPerSec      float64
+	InErrorsPerSec     float64
+	RcvbufErrorsPerSec float64
+	SndbufErrorsPerSec float64
+}
+
+// NetworkProtocolRates are the protocol counters of the network stack between two readings.
+type NetworkProtocolRates struct {
+	Interval time.Duration
+	TCP      TCPRates
+	UDP      UDPRates
+	UDPv6    UDPRates
+
+	// Counters holds the rate of every counter of NetworkProtocolStats.Counters, with the same keys.
+	// Settings and gauges, such as Tcp.RtoMin or Tcp.CurrEstab, are left out.
+	Counters map[string]float64
+}
+
+// protocolSnapshot is a reading of the protocol counters.
+type protocolSnapshot struct {
+	time  time.Time
+	stats NetworkProtocolStats
+}
+
+// NetworkProtocolSampler computes protocol counter rates from the difference between consecutive
+// readings. Sample returns immediately and covers the time since the previous call; calls from
+// several goroutines are serialized.
+type NetworkProtocolSampler struct {
+	mu   sync.Mutex
+	prev protocolSnapshot
+}
+
+// NewNetworkProtocolSampler returns a sampler with a first reading of the protocol counters.
+func NewNetworkProtocolSampler() (*NetworkProtocolSampler, error) {
+	snapshot, err := readProtocolSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &NetworkProtocolSampler{prev: snapshot}, nil
+}
+
+// Sample returns the protocol counter rates since the previous call, or since NewNetworkProtocolSampler
+// for the first one.
+func (s *NetworkProtocolSampler) Sample() (NetworkProtocolRates, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := readProtocolSnapshot()
+	if err != nil {
+		return NetworkProtocolRates{}, err
+	}
+	prev := s.prev
+	s.prev = snapshot
+	return diffProtocolStats(prev, snapshot), nil
+}
+
+// readProtocolSnapshot reads the protocol counters with the time of the reading.
+func readProtocolSnapshot() (protocolSnapshot, error) {
+	stats, err := GetNetworkProtocolStats()
+	if err != nil {
+		return protocolSnapshot{}, err
+	}
+	return protocolSnapshot{time: time.Now(), stats: stats}, nil
+}
+
+// diffProtocolStats computes the rates between two snapshots. A counter missing from either
+// reading, e.g. the IPv6 ones right after IPv6 is enabled, has no rate: its rate stays zero and
+// it is left out of Counters.
+func diffProtocolStats(prev, cur protocolSnapshot) NetworkProtocolRates {
+	interval := cur.time.Sub(prev.time)
+	rates := NetworkProtocolRates{Interval: interval, Counters: make(map[string]float64)}
+	seconds := interval.Seconds()
+	delta := func(name string) (uint64, bool) {
+		before, ok := prev.stats.Counters[name]
+		now, okNow := cur.stats.Counters[name]
+		if !ok || !okNow {
+			return 0, false
+		}
+		return counterDelta(before, now), true
+	}
+	perSecond := func(name string) float64 {
+		if d, ok := delta(name); ok && seconds > 0 {
+			return float64(d) / seconds
+		}
+		return 0
+	}
+
+	for name := range cur.stats.Counters {
+		if _, ok := prev.stats.Counters[name]; ok && !protocolGauges[name] {
+			rates.Counters[name] = perSecond(name)
+		}
+	}
+
+	rates.TCP = TCPRates{
+		ActiveOpensPerSec:     perSecond("Tcp.ActiveOpens"),
+		PassiveOpensPerSec:    perSecond("Tcp.PassiveOpens"),
+		AttemptFailsPerSec:    perSecond("Tcp.AttemptFails"),
+		EstabResetsPerSec:     perSecond("Tcp.EstabResets"),
+		InSegsPerSec:          perSecond("Tcp.InSegs"),
+		OutSegsPerSec:         perSecond("Tcp.OutSegs"),
+		RetransSegsPerSec:     perSecond("Tcp.RetransSegs"),
+		OutRstsPerSec:         perSecond("Tcp.OutRsts"),
+		InErrsPerSec:          perSecond("Tcp.InErrs"),
+		ListenOverflowsPerSec: perSecond("TcpExt.ListenOverflows"),
+		ListenDropsPerSec:     perSecond("TcpExt.ListenDrops"),
+		TimeoutsPerSec:        perSecond("TcpExt.TCPTimeouts"),
+	}
+	if sent, ok := delta("Tcp.OutSegs"); ok && sent > 0 {
+		retransmitted, _ := delta("Tcp.RetransSegs")
+		rates.TCP.RetransPercent = float64(retransmitted) / float64(sent) * 100
+	}
+
+	udp := func(section string) UDPRates {
+		return UDPRates{
+			InDatagramsPerSec:  perSecond(section + ".InDatagrams"),
+			OutDatagramsPerSec: perSecond(section + ".OutDatagrams"),
+			NoPortsPerSec:      perSecond(section + ".NoPorts"),
+			InErrorsPerSec:     perSecond(section + ".InErrors"),
+			RcvbufErrorsPerSec: perSecond(section + ".RcvbufErrors"),
+			SndbufErrorsPerSec: perSecond(section + ".SndbufErrors"),
+		}
+	}
+	rates.UDP = udp("Udp")
+	rates.UDPv6 = udp("Udp6")
+	return rates
+}
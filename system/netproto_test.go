@@ -0,0 +1,130 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeProcFixture(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadSNMPTable(t *testing.T) {
+	snmp := writeProcFixture(t, "snmp", ""+
+		"Ip: Forwarding DefaultTTL InReceives InHdrErrors InAddrErrors ForwDatagrams InUnknownProtos InDiscards InDelivers OutRequests\n"+
+		"Ip: 1 64 12126 3 0 7 0 0 12126 12068\n"+
+		"Tcp: RtoAlgorithm RtoMin RtoMax MaxConn ActiveOpens PassiveOpens AttemptFails EstabResets CurrEstab InSegs OutSegs RetransSegs InErrs OutRsts InCsumErrors\n"+
+		"Tcp: 1 200 120000 -1 107 65 20 45 2 12078 12078 1 0 31 0\n"+
+		"Udp: InDatagrams NoPorts InErrors OutDatagrams RcvbufErrors SndbufErrors InCsumErrors IgnoredMulti MemErrors\n"+
+		"Udp: 48 2 1 48 1 0 0 0 0\n")
+	netstat := writeProcFixture(t, "netstat", ""+
+		"TcpExt: SyncookiesSent EmbryonicRsts ListenOverflows ListenDrops TCPTimeouts\n"+
+		"TcpExt: 4 1 9 11 30\n"+
+		"IpExt: InNoRoutes InTruncatedPkts\n"+
+		"IpExt: 0 0\n")
+
+	counters := make(map[string]uint64)
+	if err := readSNMPTable(snmp, counters); err != nil {
+		t.Fatal(err)
+	}
+	if err := readSNMPTable(netstat, counters); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := counters["Tcp.MaxConn"]; ok {
+		t.Error("the negative Tcp.MaxConn was kept")
+	}
+	stats := newNetworkProtocolStats(counters)
+	if stats.IP.InReceives != 12126 || stats.IP.InHdrErrors != 3 || stats.IP.ForwDatagrams != 7 {
+		t.Errorf("IP = %+v", stats.IP)
+	}
+	wantTCP := TCPStats{
+		ActiveOpens: 107, PassiveOpens: 65, AttemptFails: 20, EstabResets: 45, CurrEstab: 2,
+		InSegs: 12078, OutSegs: 12078, RetransSegs: 1, OutRsts: 31,
+		SyncookiesSent: 4, EmbryonicRsts: 1, ListenOverflows: 9, ListenDrops: 11, Timeouts: 30,
+	}
+	if stats.TCP != wantTCP {
+		t.Errorf("TCP = %+v, want %+v", stats.TCP, wantTCP)
+	}
+	if want := (UDPStats{InDatagrams: 48, NoPorts: 2, InErrors: 1, OutDatagrams: 48, RcvbufErrors: 1}); stats.UDP != want {
+		t.Errorf("UDP = %+v, want %+v", stats.UDP, want)
+	}
+	if counters["IpExt.InNoRoutes"] != 0 || len(counters) != 10+14+9+5+2 {
+		t.Errorf("%d counters: %v", len(counters), counters)
+	}
+}
+
+func TestReadSNMPTableMismatchedSection(t *testing.T) {
+	path := writeProcFixture(t, "snmp", "Ip: Forwarding DefaultTTL\nTcp: 1 64\n")
+	if err := readSNMPTable(path, make(map[string]uint64)); err == nil {
+		t.Error("a names line followed by the values of another section was accepted")
+	}
+}
+
+func TestReadSNMP6(t *testing.T) {
+	path := writeProcFixture(t, "snmp6", ""+
+		"Ip6InReceives                   \t3\n"+
+		"Ip6OutForwDatagrams             \t5\n"+
+		"Icmp6InMsgs                     \t2\n"+
+		"Icmp6InType133                  \t1\n"+
+		"Udp6InDatagrams                 \t40\n"+
+		"Udp6RcvbufErrors                \t6\n"+
+		"UdpLite6InDatagrams             \t9\n"+
+		"malformed line here\n"+
+		"Ip6InNoRoutes                   \tbad\n")
+
+	counters := make(map[string]uint64)
+	if err := readSNMP6(path, counters); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]uint64{
+		"Ip6.InReceives":       3,
+		"Ip6.OutForwDatagrams": 5,
+		"Icmp6.InMsgs":         2,
+		"Icmp6.InType133":      1,
+		"Udp6.InDatagrams":     40,
+		"Udp6.RcvbufErrors":    6,
+		"UdpLite6.InDatagrams": 9,
+	}
+	if !reflect.DeepEqual(counters, want) {
+		t.Errorf("counters = %v, want %v", counters, want)
+	}
+	stats := newNetworkProtocolStats(counters)
+	if stats.IPv6.ForwDatagrams != 5 || stats.UDPv6.InDatagrams != 40 || stats.UDPv6.RcvbufErrors != 6 {
+		t.Errorf("IPv6 = %+v, UDPv6 = %+v", stats.IPv6, stats.UDPv6)
+	}
+}
+
+func TestDiffProtocolStatsSkipsNewCounters(t *testing.T) {
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	snapshot := func(at time.Time, counters map[string]uint64) protocolSnapshot {
+		return protocolSnapshot{time: at, stats: newNetworkProtocolStats(counters)}
+	}
+	prev := snapshot(start, map[string]uint64{
+		"Tcp.OutSegs": 1000, "Tcp.RetransSegs": 10, "Tcp.CurrEstab": 5,
+	})
+	// IPv6 was enabled between the readings: its counters cover the time since then, not the interval.
+	cur := snapshot(start.Add(2*time.Second), map[string]uint64{
+		"Tcp.OutSegs": 1200, "Tcp.RetransSegs": 20, "Tcp.CurrEstab": 9,
+		"Udp6.InDatagrams": 1_000_000, "Ip6.InReceives": 5_000_000,
+	})
+
+	rates := diffProtocolStats(prev, cur)
+	want := map[string]float64{"Tcp.OutSegs": 100, "Tcp.RetransSegs": 5}
+	if !reflect.DeepEqual(rates.Counters, want) {
+		t.Errorf("counters = %v, want %v", rates.Counters, want)
+	}
+	if rates.UDPv6.InDatagramsPerSec != 0 {
+		t.Errorf("UDPv6 rate = %v for a counter that was not in the previous reading", rates.UDPv6.InDatagramsPerSec)
+	}
+	if rates.TCP.OutSegsPerSec != 100 || rates.TCP.RetransPercent != 5 {
+		t.Errorf("TCP = %+v", rates.TCP)
+	}
+}
@@ -0,0 +1,204 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// InterfaceRates is the traffic of one network interface between two samples, per second.
+type InterfaceRates struct {
+	Name            string
+	RxBytesPerSec   float64
+	TxBytesPerSec   float64
+	RxPacketsPerSec float64
+	TxPacketsPerSec float64
+	RxErrorsPerSec  float64
+	TxErrorsPerSec  float64
+	RxDropsPerSec   float64
+	TxDropsPerSec   float64
+}
+
+// NetworkRates is the traffic of the network interfaces between two readings of /proc/net/dev.
+// An interface created between the two readings, such as a new container veth, is left out until the next sample.
+type NetworkRates struct {
+	Interval   time.Duration
+	Interfaces []InterfaceRates
+}
+
+// netDevSnapshot is a reading of /proc/net/dev.
+type netDevSnapshot struct {
+	time     time.Time
+	counters map[string][2]InterfaceCounters
+}
+
+// NetworkSampler computes network rates from the difference between consecutive readings of
+// /proc/net/dev. Sample returns immediately and covers the time since the previous call.
+// It serializes reading the counters and replacing the previous reading, so it can be called
+// from several goroutines.
+type NetworkSampler struct {
+	mu   sync.Mutex
+	prev netDevSnapshot
+}
+
+// NewNetworkSampler returns a sampler with a first reading of /proc/net/dev.
+func NewNetworkSampler() (*NetworkSampler, error) {
+	snapshot, err := readNetDevSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &NetworkSampler{prev: snapshot}, nil
+}
+
+// Sample returns the traffic of every interface since the previous call, or since NewNetworkSampler
+// for the first one, sorted by interface name.
+func (s *NetworkSampler) Sample() (NetworkRates, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := readNetDevSnapshot()
+	if err != nil {
+		return NetworkRates{}, err
+	}
+	prev := s.prev
+	s.prev = snapshot
+	return diffNetDev(prev, snapshot), nil
+}
+
+// hasInterface reports whether the latest reading of the sampler has the named interface.
+func (s *NetworkSampler) hasInterface(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.prev.counters[name]
+	return ok
+}
+
+// readNetDevSnapshot reads /proc/net/dev with the time of the reading.
+func readNetDevSnapshot() (netDevSnapshot, error) {
+	counters, err := readNetDev()
+	if err != nil {
+		return netDevSnapshot{}, err
+	}
+	return netDevSnapshot{time: time.Now(), counters: counters}, nil
+}
+
+// diffNetDev computes the rates between two snapshots.
+func diffNetDev(prev, cur netDevSnapshot) NetworkRates {
+	interval := cur.time.Sub(prev.time)
+	rates := NetworkRates{Interval: interval}
+	seconds := interval.Seconds()
+
+	for name, now := range cur.counters {
+		// The counters of an interface created since the previous reading cover its whole life.
+		before, ok := prev.counters[name]
+		if !ok {
+			continue
+		}
+		perSecond := func(prev, cur uint64) float64 {
+			if seconds <= 0 {
+				return 0
+			}
+			return float64(counterDelta(prev, cur)) / seconds
+		}
+		rates.Interfaces = append(rates.Interfaces, InterfaceRates{
+			Name:            name,
+			RxBytesPerSec:   perSecond(before[0].Bytes, now[0].Bytes),
+			TxBytesPerSec:   perSecond(before[1].Bytes, now[1].Bytes),
+			RxPacketsPerSec: perSecond(before[0].Packets, now[0].Packets),
+			TxPacketsPerSec: perSecond(before[1].Packets, now[1].Packets),
+			RxErrorsPerSec:  perSecond(before[0].Errors, now[0].Errors),
+			TxErrorsPerSec:  perSecond(before[1].Errors, now[1].Errors),
+			RxDropsPerSec:   perSecond(before[0].Drops, now[0].Drops),
+			TxDropsPerSec:   perSecond(before[1].Drops, now[1].Drops),
+		})
+	}
+	sort.Slice(rates.Interfaces, func(i, j int) bool { return rates.Interfaces[i].Name < rates.Interfaces[j].Name })
+	return rates
+}
+
+// NetworkMonitorOptions configures MonitorNetworkRatesWith.
+type NetworkMonitorOptions struct {
+	Interfaces []string      // Interfaces to report. Empty, or "all", reports every interface.
+	Interval   time.Duration // Time between samples. Defaults to 1s.
+}
+
+// MonitorNetworkRatesWith sends the traffic rates of the selected interfaces every interval until
+// ctx is done, when both channels are closed. Errors, such as a selected interface that does not
+// exist, are sent on the error channel without stopping the monitor; an error is dropped while the
+// previous one is unread.
+func MonitorNetworkRatesWith(ctx context.Context, opts NetworkMonitorOptions) (<-chan NetworkRates, <-chan error) {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultSampleWindow
+	}
+	all := len(opts.Interfaces) == 0 || containsString(opts.Interfaces, "all")
+	samples := make(chan NetworkRates)
+	errs := make(chan error, 1)
+	report := func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}
+
+	go func() {
+		defer close(samples)
+		defer close(errs)
+
+		sampler, err := NewNetworkSampler()
+		if err != nil {
+			report(err)
+		}
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if sampler == nil {
+				// The first reading failed; start over from this tick.
+				if sampler, err = NewNetworkSampler(); err != nil {
+					report(err)
+				}
+				continue
+			}
+			rates, err := sampler.Sample()
+			if err != nil {
+				report(err)
+				continue
+			}
+			if !all {
+				rates.Interfaces = selectInterfaces(rates.Interfaces, opts.Interfaces, sampler.hasInterface, report)
+			}
+
+			select {
+			case samples <- rates:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return samples, errs
+}
+
+// selectInterfaces keeps the named interfaces, in the order they were requested, reporting the
+// ones that do not exist. An interface that exists but has no rates yet, because it was created
+// since the previous reading, is left out without an error.
+func selectInterfaces(rates []InterfaceRates, names []string, exists func(string) bool, report func(error)) []InterfaceRates {
+	selected := make([]InterfaceRates, 0, len(names))
+	for _, name := range names {
+		i := sort.Search(len(rates), func(i int) bool { return rates[i].Name >= name })
+		if i == len(rates) || rates[i].Name != name {
+			if !exists(name) {
+				report(fmt.Errorf("network interface %s not found", name))
+			}
+			continue
+		}
+		selected = append(selected, rates[i])
+	}
+	return selected
+}
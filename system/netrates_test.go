@@ -0,0 +1,50 @@
+package system
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffNetDevSkipsNewInterfaces(t *testing.T) {
+	start := time.Now()
+	prev := netDevSnapshot{
+		time:     start,
+		counters: map[string][2]InterfaceCounters{"eth0": {{Bytes: 1000}, {Bytes: 500}}},
+	}
+	cur := netDevSnapshot{
+		time: start.Add(2 * time.Second),
+		counters: map[string][2]InterfaceCounters{
+			"eth0":     {{Bytes: 3000}, {Bytes: 1500}},
+			"veth1a2b": {{Bytes: 1 << 40}, {Bytes: 1 << 40}},
+		},
+	}
+
+	rates := diffNetDev(prev, cur)
+	if len(rates.Interfaces) != 1 || rates.Interfaces[0].Name != "eth0" {
+		t.Fatalf("interfaces = %+v, want only eth0", rates.Interfaces)
+	}
+	if got := rates.Interfaces[0]; got.RxBytesPerSec != 1000 || got.TxBytesPerSec != 500 {
+		t.Errorf("eth0 rates = %v/%v, want 1000/500", got.RxBytesPerSec, got.TxBytesPerSec)
+	}
+}
+
+func TestSelectInterfaces(t *testing.T) {
+	rates := []InterfaceRates{{Name: "eth0", RxBytesPerSec: 1}, {Name: "lo", RxBytesPerSec: 2}, {Name: "wlan0", RxBytesPerSec: 3}}
+	// veth9f8e was created since the previous reading: it exists but has no rates yet.
+	exists := func(name string) bool { return name != "eth9" }
+	var errs []string
+	report := func(err error) { errs = append(errs, err.Error()) }
+
+	selected := selectInterfaces(rates, []string{"wlan0", "veth9f8e", "eth9", "eth0"}, exists, report)
+	var names []string
+	for _, r := range selected {
+		names = append(names, r.Name)
+	}
+	if want := []string{"wlan0", "eth0"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("selected = %v, want %v", names, want)
+	}
+	if want := []string{"network interface eth9 not found"}; !reflect.DeepEqual(errs, want) {
+		t.Errorf("errors = %q, want %q", errs, want)
+	}
+}
@@ -2,11 +2,13 @@ package system
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"net"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/GomdimApps/lcme/system/utils"
 )
@@ -36,26 +38,19 @@ type NetworkInfo struct {
 
 // GetNetworkInfo retrieves the network information.
 func GetNetworkInfo() NetworkInfo {
-	ipv4s, ipv6s := getIPAddresses()
-	initialStats, err := utils.GetNetworkStats()
+	info, err := GetNetworkInfoWith(context.Background(), time.Second)
 	if err != nil {
-		fmt.Println("Error getting initial network stats:", err)
-		return NetworkInfo{}
-	}
-
-	interfaceName, err := utils.GetActiveInterface(initialStats)
-	if err != nil {
-		fmt.Println("Error getting active interface:", err)
-		return NetworkInfo{}
-	}
-
-	downloadRate, uploadRate, err := utils.CalculateNetworkRates(initialStats, interfaceName)
-	if err != nil {
-		fmt.Println("Error calculating network rates:", err)
-		return NetworkInfo{}
+		fmt.Println(err)
 	}
+	return info
+}
 
-	return NetworkInfo{
+// GetNetworkInfoWith retrieves the network information, measuring the download and upload rates over window.
+// It returns early with the context error if ctx is done before the window ends. The addresses and ports
+// are still returned along with an error; only the rates are left at zero.
+func GetNetworkInfoWith(ctx context.Context, window time.Duration) (NetworkInfo, error) {
+	ipv4s, ipv6s := getIPAddresses()
+	info := NetworkInfo{
 		IPv4: ipv4s,
 		IPv6: ipv6s,
 		IPv4Ports: PortType{
@@ -66,9 +61,25 @@ func GetNetworkInfo() NetworkInfo {
 			TCP: getPortAddresses("/proc/net/tcp6"),
 			UDP: getPortAddresses("/proc/net/udp6"),
 		},
-		Download: downloadRate,
-		Upload:   uploadRate,
 	}
+
+	initialStats, err := utils.GetNetworkStats()
+	if err != nil {
+		return info, fmt.Errorf("error getting initial network stats: %v", err)
+	}
+
+	interfaceName, err := utils.GetActiveInterface(initialStats)
+	if err != nil {
+		return info, fmt.Errorf("error getting active interface: %v", err)
+	}
+
+	downloadRate, uploadRate, err := utils.CalculateNetworkRatesWindow(ctx, initialStats, interfaceName, window)
+	if err != nil {
+		return info, fmt.Errorf("error calculating network rates: %w", err)
+	}
+	info.Download = downloadRate
+	info.Upload = uploadRate
+	return info, nil
 }
 
 // getIPAddresses retrieves the IPv4 and IPv6 addresses.
@@ -158,22 +169,11 @@ func parseAddress(addr string) string {
 
 // parseIP parses a hexadecimal IP address.
 func parseIP(hexIP string) string {
-	if len(hexIP) == 8 { // IPv4
-		ip := make(net.IP, 4)
-		for i := 0; i < 4; i++ {
-			byteVal, _ := strconv.ParseUint(hexIP[i*2:i*2+2], 16, 8)
-			ip[3-i] = byte(byteVal)
-		}
-		return ip.String()
-	} else if len(hexIP) == 32 { // IPv6
-		ip := make(net.IP, 16)
-		for i := 0; i < 16; i++ {
-			byteVal, _ := strconv.ParseUint(hexIP[i*2:i*2+2], 16, 8)
-			ip[15-i] = byte(byteVal)
-		}
-		return ip.String()
+	ip := utils.ParseHexIP(hexIP)
+	if ip == nil {
+		return ""
 	}
-	return ""
+	return ip.String()
 }
 
 // parsePort parses a hexadecimal port number.
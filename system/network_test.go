@@ -0,0 +1,24 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGetNetworkInfoWithCancelledKeepsAddresses(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	info, err := GetNetworkInfoWith(ctx, time.Minute)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+	if len(info.IPv4) == 0 && len(info.IPv6) == 0 {
+		t.Error("the addresses were dropped with the context error")
+	}
+	if info.Download != 0 || info.Upload != 0 {
+		t.Errorf("rates = %d/%d, want zero", info.Download, info.Upload)
+	}
+}
@@ -0,0 +1,119 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"syscall"
+)
+
+// ListeningPort is a socket waiting for connections: a TCP socket in the LISTEN state
+// or an unconnected UDP socket bound to a port.
+type ListeningPort struct {
+	Protocol string // "tcp" or "udp".
+	Family   string // "ipv4" or "ipv6".
+	Address  net.IP // Bound address; the unspecified address 0.0.0.0 or :: means every address.
+	Port     uint16
+	PID      int // Owning process, zero when it belongs to another user and the caller is not root.
+	Command  string
+}
+
+// ListeningPorts returns every TCP and UDP socket waiting for connections with its owning process,
+// sorted by port, like ss -tulnp.
+func ListeningPorts() ([]ListeningPort, error) {
+	connections, err := GetConnectionsWith(ConnectionFilter{States: []string{"LISTEN", "UNCONN"}})
+	if err != nil {
+		return nil, err
+	}
+
+	var ports []ListeningPort
+	for _, c := range connections {
+		ports = append(ports, ListeningPort{
+			Protocol: c.Protocol,
+			Family:   c.Family,
+			Address:  c.Local.IP,
+			Port:     c.Local.Port,
+			PID:      c.PID,
+			Command:  c.Command,
+		})
+	}
+	sort.SliceStable(ports, func(i, j int) bool {
+		if ports[i].Port != ports[j].Port {
+			return ports[i].Port < ports[j].Port
+		}
+		return ports[i].Protocol < ports[j].Protocol
+	})
+	return ports, nil
+}
+
+// IsPortInUse reports whether a TCP or UDP socket is bound to port on any address, IPv4 or IPv6.
+// The socket tables are checked first; a port that is not listed is confirmed by trying to bind it,
+// which also catches sockets in other network namespaces sharing the port. A privileged port that
+// the caller is not allowed to bind is reported as in use only when it is listed.
+func IsPortInUse(port int) bool {
+	if port < 1 || port > 65535 {
+		return false
+	}
+	used, err := usedPorts()
+	if err == nil && used[uint16(port)] {
+		return true
+	}
+	err = bindPort(port)
+	return err != nil && !errors.Is(err, syscall.EACCES)
+}
+
+// FindFreePort returns the first port between rangeStart and rangeEnd, inclusive, that no
+// TCP or UDP socket uses and that the caller can bind on every address for both protocols.
+// Privileged ports below 1024 are skipped when the caller lacks CAP_NET_BIND_SERVICE.
+func FindFreePort(rangeStart, rangeEnd int) (int, error) {
+	if rangeStart < 1 || rangeEnd > 65535 || rangeStart > rangeEnd {
+		return 0, fmt.Errorf("invalid port range %d-%d", rangeStart, rangeEnd)
+	}
+	used, err := usedPorts()
+	if err != nil {
+		return 0, err
+	}
+	for port := rangeStart; port <= rangeEnd; port++ {
+		if !used[uint16(port)] && bindPort(port) == nil {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("no free port between %d and %d", rangeStart, rangeEnd)
+}
+
+// usedPorts returns the local ports of the listening TCP sockets and of every UDP socket,
+// from both the IPv4 and IPv6 tables.
+func usedPorts() (map[uint16]bool, error) {
+	connections, err := GetConnectionsWith(ConnectionFilter{SkipProcess: true})
+	if err != nil {
+		return nil, err
+	}
+	used := make(map[uint16]bool)
+	for _, c := range connections {
+		// Established TCP connections only hold an ephemeral port that does not prevent binding.
+		if c.Protocol == "udp" || c.State == "LISTEN" {
+			used[c.Local.Port] = true
+		}
+	}
+	return used, nil
+}
+
+// bindPort tries to bind port for TCP and UDP on every IPv4 and IPv6 address
+// and returns the first error.
+func bindPort(port int) error {
+	address := ":" + strconv.Itoa(port)
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	listener.Close()
+
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return err
+	}
+	conn.Close()
+	return nil
+}
@@ -0,0 +1,56 @@
+package system
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestIsPortInUseAndFindFreePort(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	if !IsPortInUse(port) {
+		t.Errorf("IsPortInUse(%d) = false for a listening port", port)
+	}
+	if got, err := FindFreePort(port, port); err == nil {
+		t.Errorf("FindFreePort(%d, %d) = %d, want an error", port, port, got)
+	}
+}
+
+func TestFindFreePortSkipsPrivilegedPorts(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can bind privileged ports")
+	}
+	data, err := os.ReadFile("/proc/sys/net/ipv4/ip_unprivileged_port_start")
+	if err != nil {
+		t.Skip("the unprivileged port range is unknown:", err)
+	}
+	if start, err := strconv.Atoi(strings.TrimSpace(string(data))); err != nil || start < 1024 {
+		t.Skipf("unprivileged users can bind ports from %s", strings.TrimSpace(string(data)))
+	}
+
+	port, err := FindFreePort(1, 1023)
+	if want := "no free port between 1 and 1023"; err == nil || err.Error() != want {
+		t.Errorf("FindFreePort(1, 1023) = %d, %v; want error %q", port, err, want)
+	}
+	if IsPortInUse(80) != isListed(t, 80) {
+		t.Error("IsPortInUse reports a privileged port as in use only because it cannot be bound")
+	}
+}
+
+// isListed reports whether port is in the socket tables.
+func isListed(t *testing.T, port uint16) bool {
+	t.Helper()
+	used, err := usedPorts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return used[port]
+}
@@ -0,0 +1,265 @@
+package system
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// LoadAverage contains the system load averages and task counts from /proc/loadavg.
+type LoadAverage struct {
+	Load1   float64 // Average number of runnable or uninterruptible tasks over 1 minute.
+	Load5   float64 // Same, over 5 minutes.
+	Load15  float64 // Same, over 15 minutes.
+	Running int     // Tasks currently runnable.
+	Total   int     // Tasks that currently exist.
+}
+
+// GetLoadAverage reads the load averages and task counts from /proc/loadavg.
+func GetLoadAverage() (LoadAverage, error) {
+	data, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return LoadAverage{}, err
+	}
+	return parseLoadAverage(string(data))
+}
+
+// parseLoadAverage parses a line such as "0.52 0.58 0.59 2/1271 12345".
+func parseLoadAverage(line string) (LoadAverage, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 {
+		return LoadAverage{}, fmt.Errorf("invalid /proc/loadavg content: %q", line)
+	}
+
+	var load LoadAverage
+	var err error
+	if load.Load1, err = strconv.ParseFloat(fields[0], 64); err != nil {
+		return LoadAverage{}, err
+	}
+	if load.Load5, err = strconv.ParseFloat(fields[1], 64); err != nil {
+		return LoadAverage{}, err
+	}
+	if load.Load15, err = strconv.ParseFloat(fields[2], 64); err != nil {
+		return LoadAverage{}, err
+	}
+	running, total, ok := strings.Cut(fields[3], "/")
+	if !ok {
+		return LoadAverage{}, fmt.Errorf("invalid task counts in /proc/loadavg: %q", fields[3])
+	}
+	if load.Running, err = strconv.Atoi(running); err != nil {
+		return LoadAverage{}, err
+	}
+	if load.Total, err = strconv.Atoi(total); err != nil {
+		return LoadAverage{}, err
+	}
+	return load, nil
+}
+
+// PressureResource names a resource tracked by pressure stall information.
+type PressureResource string
+
+const (
+	PressureCPU    PressureResource = "cpu"
+	PressureMemory PressureResource = "memory"
+	PressureIO     PressureResource = "io"
+)
+
+// ErrPressureUnsupported is returned when the kernel does not provide /proc/pressure,
+// either because it is older than 4.20 or because PSI is disabled.
+var ErrPressureUnsupported = errors.New("pressure stall information is not available")
+
+// PressureStats is one line of a /proc/pressure file: the percentage of time in which tasks
+// were stalled on the resource over the last 10, 60 and 300 seconds, and the total stall time.
+type PressureStats struct {
+	Avg10  float64
+	Avg60  float64
+	Avg300 float64
+	Total  time.Duration
+}
+
+// Pressure holds the stall information of a resource. Some is the time in which at least one task
+// was stalled; Full is the time in which all non-idle tasks were stalled at once.
+// Full is always zero for the CPU on kernels older than 5.13.
+type Pressure struct {
+	Some PressureStats
+	Full PressureStats
+}
+
+// PressureInfo contains the pressure stall information of the CPU, memory and IO.
+type PressureInfo struct {
+	CPU    Pressure
+	Memory Pressure
+	IO     Pressure
+}
+
+// GetPressure reads the pressure stall information from /proc/pressure.
+func GetPressure() (PressureInfo, error) {
+	var info PressureInfo
+	var err error
+	if info.CPU, err = GetResourcePressure(PressureCPU); err != nil {
+		return PressureInfo{}, err
+	}
+	if info.Memory, err = GetResourcePressure(PressureMemory); err != nil {
+		return PressureInfo{}, err
+	}
+	if info.IO, err = GetResourcePressure(PressureIO); err != nil {
+		return PressureInfo{}, err
+	}
+	return info, nil
+}
+
+// GetResourcePressure reads the pressure stall information of one resource.
+func GetResourcePressure(resource PressureResource) (Pressure, error) {
+	return readPressure(pressurePath(resource))
+}
+
+// readPressure parses a file in the format of /proc/pressure/cpu, e.g.
+// "some avg10=0.00 avg60=0.00 avg300=0.00 total=0" followed by the same "full" line.
+func readPressure(path string) (Pressure, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return Pressure{}, ErrPressureUnsupported
+	} else if err != nil {
+		return Pressure{}, err
+	}
+	defer file.Close()
+
+	var pressure Pressure
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		stats, err := parsePressureStats(fields[1:])
+		if err != nil {
+			return Pressure{}, err
+		}
+		switch fields[0] {
+		case "some":
+			pressure.Some = stats
+		case "full":
+			pressure.Full = stats
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		// Reading fails with EOPNOTSUPP when PSI is compiled in but disabled at boot.
+		if errors.Is(err, unix.EOPNOTSUPP) {
+			return Pressure{}, ErrPressureUnsupported
+		}
+		return Pressure{}, err
+	}
+	return pressure, nil
+}
+
+// parsePressureStats parses the fields "avg10=0.00 avg60=0.00 avg300=0.00 total=0".
+func parsePressureStats(fields []string) (PressureStats, error) {
+	var stats PressureStats
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			continue
+		}
+		var err error
+		switch key {
+		case "avg10":
+			stats.Avg10, err = strconv.ParseFloat(value, 64)
+		case "avg60":
+			stats.Avg60, err = strconv.ParseFloat(value, 64)
+		case "avg300":
+			stats.Avg300, err = strconv.ParseFloat(value, 64)
+		case "total":
+			var total uint64
+			total, err = strconv.ParseUint(value, 10, 64)
+			stats.Total = time.Duration(total) * time.Microsecond
+		}
+		if err != nil {
+			return PressureStats{}, fmt.Errorf("invalid pressure value %q: %v", field, err)
+		}
+	}
+	return stats, nil
+}
+
+// pressurePath returns the /proc/pressure file of a resource.
+func pressurePath(resource PressureResource) string {
+	return "/proc/pressure/" + string(resource)
+}
+
+// PressureTrigger describes a PSI threshold: it fires when tasks were stalled on Resource for
+// at least Stall within any Window. Window must be between 500ms and 10s; unprivileged
+// processes can only use windows that are a multiple of 2s.
+type PressureTrigger struct {
+	Resource PressureResource
+	Full     bool // Use the "full" stall time instead of "some".
+	Stall    time.Duration
+	Window   time.Duration
+}
+
+// PressureEvent is sent each time a PressureTrigger fires.
+type PressureEvent struct {
+	Trigger PressureTrigger
+	Time    time.Time
+}
+
+// WatchPressure registers a trigger with the kernel and sends an event each time it fires.
+// The kernel fires a trigger at most once per window. The channel is closed when ctx is done
+// or when the kernel stops reporting events, e.g. because the monitored cgroup was removed.
+func WatchPressure(ctx context.Context, trigger PressureTrigger) (<-chan PressureEvent, error) {
+	kind := "some"
+	if trigger.Full {
+		kind = "full"
+	}
+	if trigger.Stall <= 0 || trigger.Window <= 0 || trigger.Stall > trigger.Window {
+		return nil, fmt.Errorf("invalid pressure trigger: stall %s in window %s", trigger.Stall, trigger.Window)
+	}
+
+	// The descriptor is opened with unix.Open rather than os.OpenFile: it is only polled, and
+	// os.File.Fd would switch it to blocking mode behind the runtime poller.
+	path := pressurePath(trigger.Resource)
+	fd, err := unix.Open(path, unix.O_RDWR|unix.O_CLOEXEC, 0)
+	if err == unix.ENOENT {
+		return nil, ErrPressureUnsupported
+	} else if err != nil {
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+	threshold := fmt.Sprintf("%s %d %d\x00", kind, trigger.Stall.Microseconds(), trigger.Window.Microseconds())
+	if _, err := unix.Write(fd, []byte(threshold)); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("error registering pressure trigger: %v", err)
+	}
+
+	events := make(chan PressureEvent)
+	go func() {
+		defer close(events)
+		defer unix.Close(fd)
+
+		fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLPRI}}
+		for ctx.Err() == nil {
+			// Wake up regularly to notice when ctx is done.
+			n, err := unix.Poll(fds, 250)
+			if err == unix.EINTR || n == 0 {
+				continue
+			} else if err != nil {
+				return
+			}
+			if fds[0].Revents&unix.POLLERR != 0 {
+				return
+			}
+			if fds[0].Revents&unix.POLLPRI != 0 {
+				select {
+				case events <- PressureEvent{Trigger: trigger, Time: time.Now()}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
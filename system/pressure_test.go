@@ -0,0 +1,115 @@
+package system
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLoadAverage(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    LoadAverage
+		wantErr bool
+	}{
+		{"0.52 0.58 0.59 2/1271 12345\n", LoadAverage{Load1: 0.52, Load5: 0.58, Load15: 0.59, Running: 2, Total: 1271}, false},
+		{"12.00 8.50 4.25 17/3042 998877", LoadAverage{Load1: 12, Load5: 8.5, Load15: 4.25, Running: 17, Total: 3042}, false},
+		{"0.52 0.58 0.59", LoadAverage{}, true},
+		{"", LoadAverage{}, true},
+		{"0.52 0.58 0.59 21271 12345", LoadAverage{}, true},
+		{"0.52 0.58 0.59 2/ 12345", LoadAverage{}, true},
+		{"0.52 0.58 0.59 x/1271 12345", LoadAverage{}, true},
+		{"0.52 abc 0.59 2/1271 12345", LoadAverage{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseLoadAverage(tt.line)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseLoadAverage(%q) error = %v, want error %v", tt.line, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseLoadAverage(%q) = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParsePressureStats(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    PressureStats
+		wantErr bool
+	}{
+		{"avg10=1.25 avg60=0.50 avg300=0.10 total=123456",
+			PressureStats{Avg10: 1.25, Avg60: 0.5, Avg300: 0.1, Total: 123456 * time.Microsecond}, false},
+		{"avg10=0.00 avg60=0.00 avg300=0.00 total=0", PressureStats{}, false},
+		// Unknown keys and fields without a value are ignored.
+		{"avg10=2.00 future=7 junk", PressureStats{Avg10: 2}, false},
+		{"avg10=0.00 avg60=0.00 avg300=0.00 total=-5", PressureStats{}, true},
+		{"avg10=0.00 avg60=0.00 avg300=0.00 total=12.5", PressureStats{}, true},
+		{"avg10=high avg60=0.00 avg300=0.00 total=0", PressureStats{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parsePressureStats(strings.Fields(tt.line))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parsePressureStats(%q) error = %v, want error %v", tt.line, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parsePressureStats(%q) = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestReadPressure(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Pressure
+		wantErr bool
+	}{
+		{"some and full", "" +
+			"some avg10=3.50 avg60=2.25 avg300=1.00 total=9876543\n" +
+			"full avg10=1.50 avg60=0.75 avg300=0.25 total=4321000\n",
+			Pressure{
+				Some: PressureStats{Avg10: 3.5, Avg60: 2.25, Avg300: 1, Total: 9876543 * time.Microsecond},
+				Full: PressureStats{Avg10: 1.5, Avg60: 0.75, Avg300: 0.25, Total: 4321 * time.Millisecond},
+			}, false},
+		// The CPU has no "full" line before Linux 5.13.
+		{"missing full", "some avg10=0.10 avg60=0.20 avg300=0.30 total=42\n",
+			Pressure{Some: PressureStats{Avg10: 0.1, Avg60: 0.2, Avg300: 0.3, Total: 42 * time.Microsecond}}, false},
+		{"bad total", "" +
+			"some avg10=0.00 avg60=0.00 avg300=0.00 total=0\n" +
+			"full avg10=0.00 avg60=0.00 avg300=0.00 total=nan\n",
+			Pressure{}, true},
+	}
+	for _, tt := range tests {
+		got, err := readPressure(writeProcFixture(t, "cpu", tt.content))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: pressure = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadPressureUnsupported(t *testing.T) {
+	if _, err := readPressure(filepath.Join(t.TempDir(), "cpu")); err != ErrPressureUnsupported {
+		t.Errorf("error for a missing file = %v, want ErrPressureUnsupported", err)
+	}
+}
+
+func TestWatchPressureInvalidTrigger(t *testing.T) {
+	for _, trigger := range []PressureTrigger{
+		{Resource: PressureMemory, Stall: 0, Window: 2 * time.Second},
+		{Resource: PressureMemory, Stall: time.Second, Window: 0},
+		{Resource: PressureMemory, Stall: 3 * time.Second, Window: 2 * time.Second},
+	} {
+		if _, err := WatchPressure(context.Background(), trigger); err == nil {
+			t.Errorf("WatchPressure(%+v) succeeded, want an error", trigger)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package system
+
+import "github.com/GomdimApps/lcme/system/utils"
+
+// Route flags, from include/uapi/linux/route.h.
+const (
+	RouteFlagUp      = utils.RouteFlagUp      // The route is usable.
+	RouteFlagGateway = utils.RouteFlagGateway // The destination is reached through a gateway.
+	RouteFlagHost    = utils.RouteFlagHost    // The destination is a single host.
+	RouteFlagReject  = utils.RouteFlagReject  // Packets to the destination are rejected.
+)
+
+// ErrNoDefaultRoute is returned by DefaultRoute when neither IPv4 nor IPv6 has a usable default route.
+var ErrNoDefaultRoute = utils.ErrNoDefaultRoute
+
+// Route is an entry of the kernel routing table, from /proc/net/route or /proc/net/ipv6_route.
+type Route = utils.Route
+
+// GetRoutes returns the IPv4 and IPv6 routing tables.
+func GetRoutes() ([]Route, error) {
+	return utils.GetRoutes()
+}
+
+// DefaultRoute returns the usable default route with the lowest metric, preferring IPv4,
+// which gives the default gateway and the egress interface.
+func DefaultRoute() (Route, error) {
+	return utils.DefaultRoute()
+}
@@ -0,0 +1,190 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TripPoint is a temperature at which a thermal zone takes an action, such as throttling or shutting down.
+type TripPoint struct {
+	Type    string // "active", "passive", "hot" or "critical".
+	Celsius float64
+}
+
+// ThermalZone is a temperature reported by /sys/class/thermal/thermal_zone*.
+type ThermalZone struct {
+	Name       string // Directory name, e.g. "thermal_zone0".
+	Type       string // Sensor type, e.g. "x86_pkg_temp" or "acpitz".
+	Celsius    float64
+	Critical   float64 // Critical trip point, or zero when the zone has none.
+	TripPoints []TripPoint
+}
+
+// HwmonSensor is one input of a hwmon chip. Temperatures are in degrees Celsius,
+// fan speeds in RPM and voltages in volts. Thresholds the chip does not report are zero.
+type HwmonSensor struct {
+	Label    string // Label from the driver, or the input name such as "temp1" when it has none.
+	Value    float64
+	Min      float64
+	Max      float64
+	Critical float64
+	Alarm    bool
+}
+
+// HwmonChip is a hardware monitoring chip from /sys/class/hwmon/hwmon*.
+type HwmonChip struct {
+	Name         string // Driver name, e.g. "coretemp", "nct6775" or "nvme".
+	Path         string
+	Temperatures []HwmonSensor
+	Fans         []HwmonSensor
+	Voltages     []HwmonSensor
+}
+
+// SensorsInfo contains the thermal zones and hardware monitoring chips of the machine.
+type SensorsInfo struct {
+	ThermalZones []ThermalZone
+	Chips        []HwmonChip
+}
+
+// GetSensors reads the temperatures, fan speeds and voltages exposed under /sys.
+func GetSensors() (SensorsInfo, error) {
+	return GetSensorsFrom("/sys")
+}
+
+// GetSensorsFrom reads the sensors from a sysfs tree mounted at root, e.g. the host's /sys
+// bind-mounted into a container. Machines without sensors, such as most virtual machines,
+// return an empty SensorsInfo.
+func GetSensorsFrom(root string) (SensorsInfo, error) {
+	if _, err := os.Stat(root); err != nil {
+		return SensorsInfo{}, fmt.Errorf("error reading sysfs: %v", err)
+	}
+
+	var info SensorsInfo
+	zones, _ := filepath.Glob(filepath.Join(root, "class", "thermal", "thermal_zone*"))
+	sort.Slice(zones, func(i, j int) bool { return naturalLess(zones[i], zones[j]) })
+	for _, dir := range zones {
+		if zone, err := readThermalZone(dir); err == nil {
+			info.ThermalZones = append(info.ThermalZones, zone)
+		}
+	}
+
+	chips, _ := filepath.Glob(filepath.Join(root, "class", "hwmon", "hwmon*"))
+	sort.Slice(chips, func(i, j int) bool { return naturalLess(chips[i], chips[j]) })
+	for _, dir := range chips {
+		info.Chips = append(info.Chips, readHwmonChip(dir))
+	}
+	return info, nil
+}
+
+// readThermalZone reads a thermal zone directory. Zones whose temperature cannot be read,
+// e.g. because the device is suspended, are skipped.
+func readThermalZone(dir string) (ThermalZone, error) {
+	millidegrees, err := readSysfsInt(filepath.Join(dir, "temp"))
+	if err != nil {
+		return ThermalZone{}, err
+	}
+	zone := ThermalZone{Name: filepath.Base(dir), Celsius: float64(millidegrees) / 1000}
+	zone.Type, _ = readSysfsString(filepath.Join(dir, "type"))
+
+	for i := 0; ; i++ {
+		prefix := filepath.Join(dir, "trip_point_"+strconv.Itoa(i))
+		tripType, err := readSysfsString(prefix + "_type")
+		if err != nil {
+			break
+		}
+		temp, err := readSysfsInt(prefix + "_temp")
+		if err != nil {
+			continue
+		}
+		trip := TripPoint{Type: tripType, Celsius: float64(temp) / 1000}
+		zone.TripPoints = append(zone.TripPoints, trip)
+		if tripType == "critical" {
+			zone.Critical = trip.Celsius
+		}
+	}
+	return zone, nil
+}
+
+// readHwmonChip reads the temperature, fan and voltage inputs of a hwmon chip. Some drivers
+// keep their attributes in the device directory instead of the hwmon one.
+func readHwmonChip(dir string) HwmonChip {
+	chip := HwmonChip{Path: dir}
+	chip.Name, _ = readSysfsString(filepath.Join(dir, "name"))
+
+	attrDir := dir
+	if inputs, _ := filepath.Glob(filepath.Join(dir, "*_input")); len(inputs) == 0 {
+		attrDir = filepath.Join(dir, "device")
+	}
+	// Temperatures are in millidegrees, voltages in millivolts and fans already in RPM.
+	chip.Temperatures = readHwmonSensors(attrDir, "temp", 1000)
+	chip.Fans = readHwmonSensors(attrDir, "fan", 1)
+	chip.Voltages = readHwmonSensors(attrDir, "in", 1000)
+	return chip
+}
+
+// readHwmonSensors reads the inputs named <kind><n>_input, dividing the values by scale.
+func readHwmonSensors(dir, kind string, scale float64) []HwmonSensor {
+	inputs, _ := filepath.Glob(filepath.Join(dir, kind+"*_input"))
+	sort.Slice(inputs, func(i, j int) bool { return naturalLess(inputs[i], inputs[j]) })
+
+	var sensors []HwmonSensor
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), "_input")
+		if _, err := strconv.Atoi(strings.TrimPrefix(name, kind)); err != nil {
+			continue // e.g. "intrusion0_input" when kind is "in".
+		}
+		value, err := readSysfsInt(input)
+		if err != nil {
+			continue
+		}
+		prefix := filepath.Join(dir, name)
+		sensor := HwmonSensor{Label: name, Value: float64(value) / scale}
+		if label, err := readSysfsString(prefix + "_label"); err == nil && label != "" {
+			sensor.Label = label
+		}
+		if v, err := readSysfsInt(prefix + "_min"); err == nil {
+			sensor.Min = float64(v) / scale
+		}
+		if v, err := readSysfsInt(prefix + "_max"); err == nil {
+			sensor.Max = float64(v) / scale
+		}
+		if v, err := readSysfsInt(prefix + "_crit"); err == nil {
+			sensor.Critical = float64(v) / scale
+		}
+		if v, err := readSysfsInt(prefix + "_alarm"); err == nil {
+			sensor.Alarm = v != 0
+		}
+		sensors = append(sensors, sensor)
+	}
+	return sensors
+}
+
+// naturalLess orders paths numbered in their last element numerically, so "hwmon10" comes after "hwmon2".
+func naturalLess(a, b string) bool {
+	prefixA, numA := splitBaseNumber(a)
+	prefixB, numB := splitBaseNumber(b)
+	if prefixA != prefixB {
+		return a < b
+	}
+	return numA < numB
+}
+
+// splitBaseNumber removes the first number of the last path element, turning
+// ".../temp12_input" into ".../temp_input" and 12.
+func splitBaseNumber(s string) (string, int) {
+	base := s[strings.LastIndex(s, "/")+1:]
+	start := strings.IndexAny(base, "0123456789")
+	if start < 0 {
+		return s, -1
+	}
+	end := start
+	for end < len(base) && base[end] >= '0' && base[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(base[start:end])
+	return s[:len(s)-len(base)] + base[:start] + base[end:], n
+}
@@ -0,0 +1,116 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeSysfsFixture creates the files of a fake sysfs tree under root.
+func writeSysfsFixture(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGetSensorsFromThermalZones(t *testing.T) {
+	root := t.TempDir()
+	writeSysfsFixture(t, root, map[string]string{
+		"class/thermal/thermal_zone0/type":              "acpitz",
+		"class/thermal/thermal_zone0/temp":              "27800",
+		"class/thermal/thermal_zone0/trip_point_0_type": "passive",
+		"class/thermal/thermal_zone0/trip_point_0_temp": "95000",
+		"class/thermal/thermal_zone0/trip_point_1_type": "critical",
+		"class/thermal/thermal_zone0/trip_point_1_temp": "105000",
+		"class/thermal/thermal_zone10/type":             "x86_pkg_temp",
+		"class/thermal/thermal_zone10/temp":             "45000",
+		"class/thermal/thermal_zone2/type":              "iwlwifi_1",
+		"class/thermal/thermal_zone2/temp":              "-500",
+		// A suspended device: the temperature cannot be read.
+		"class/thermal/thermal_zone3/type": "pch_cannonlake",
+	})
+
+	info, err := GetSensorsFrom(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ThermalZone{
+		{
+			Name: "thermal_zone0", Type: "acpitz", Celsius: 27.8, Critical: 105,
+			TripPoints: []TripPoint{{Type: "passive", Celsius: 95}, {Type: "critical", Celsius: 105}},
+		},
+		{Name: "thermal_zone2", Type: "iwlwifi_1", Celsius: -0.5},
+		{Name: "thermal_zone10", Type: "x86_pkg_temp", Celsius: 45},
+	}
+	if !reflect.DeepEqual(info.ThermalZones, want) {
+		t.Errorf("thermal zones = %+v, want %+v", info.ThermalZones, want)
+	}
+}
+
+func TestGetSensorsFromHwmon(t *testing.T) {
+	root := t.TempDir()
+	writeSysfsFixture(t, root, map[string]string{
+		"class/hwmon/hwmon0/name":         "coretemp",
+		"class/hwmon/hwmon0/temp1_input":  "52000",
+		"class/hwmon/hwmon0/temp1_label":  "Package id 0",
+		"class/hwmon/hwmon0/temp1_max":    "80000",
+		"class/hwmon/hwmon0/temp1_crit":   "100000",
+		"class/hwmon/hwmon0/temp10_input": "49000",
+		"class/hwmon/hwmon0/temp2_input":  "48000",
+		"class/hwmon/hwmon0/temp2_alarm":  "1",
+		// Older drivers keep their attributes in the device directory.
+		"class/hwmon/hwmon1/name":                    "nct6775",
+		"class/hwmon/hwmon1/device/fan1_input":       "1200",
+		"class/hwmon/hwmon1/device/fan1_min":         "300",
+		"class/hwmon/hwmon1/device/in0_input":        "1040",
+		"class/hwmon/hwmon1/device/in0_label":        "Vcore",
+		"class/hwmon/hwmon1/device/intrusion0_input": "0",
+	})
+
+	info, err := GetSensorsFrom(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Chips) != 2 {
+		t.Fatalf("chips = %+v, want 2", info.Chips)
+	}
+
+	coretemp := info.Chips[0]
+	wantTemps := []HwmonSensor{
+		{Label: "Package id 0", Value: 52, Max: 80, Critical: 100},
+		{Label: "temp2", Value: 48, Alarm: true},
+		{Label: "temp10", Value: 49},
+	}
+	if coretemp.Name != "coretemp" || !reflect.DeepEqual(coretemp.Temperatures, wantTemps) {
+		t.Errorf("coretemp = %+v, want temperatures %+v", coretemp, wantTemps)
+	}
+
+	nct := info.Chips[1]
+	if nct.Name != "nct6775" || len(nct.Temperatures) != 0 {
+		t.Errorf("nct6775 = %+v", nct)
+	}
+	if want := []HwmonSensor{{Label: "fan1", Value: 1200, Min: 300}}; !reflect.DeepEqual(nct.Fans, want) {
+		t.Errorf("fans = %+v, want %+v", nct.Fans, want)
+	}
+	if want := []HwmonSensor{{Label: "Vcore", Value: 1.04}}; !reflect.DeepEqual(nct.Voltages, want) {
+		t.Errorf("voltages = %+v, want %+v", nct.Voltages, want)
+	}
+}
+
+func TestGetSensorsFromEmptyAndMissing(t *testing.T) {
+	info, err := GetSensorsFrom(t.TempDir())
+	if err != nil || len(info.ThermalZones) != 0 || len(info.Chips) != 0 {
+		t.Errorf("empty sysfs = %+v, %v; want no sensors and no error", info, err)
+	}
+	if _, err := GetSensorsFrom(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("a missing sysfs root returned no error")
+	}
+}
@@ -0,0 +1,101 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// soAcceptCon is the flag of listening sockets in /proc/net/unix.
+const soAcceptCon = 0x10000
+
+// UnixSocket is a unix domain socket from /proc/net/unix.
+// PID and Command are zero when the owning process is unknown, as in Connection.
+type UnixSocket struct {
+	Path      string // Bound path, "@name" for abstract sockets, empty for unbound ones.
+	Type      string // "stream", "dgram" or "seqpacket".
+	State     string // "UNCONNECTED", "CONNECTING", "CONNECTED" or "DISCONNECTING".
+	Listening bool
+	Inode     uint64
+	PID       int
+	Command   string
+}
+
+// unixSocketTypes are the values of the Type column.
+var unixSocketTypes = map[uint64]string{
+	1: "stream",
+	2: "dgram",
+	5: "seqpacket",
+}
+
+// unixSocketStates are the values of the St column, from include/uapi/linux/net.h.
+var unixSocketStates = map[uint64]string{
+	1: "UNCONNECTED",
+	2: "CONNECTING",
+	3: "CONNECTED",
+	4: "DISCONNECTING",
+}
+
+// GetUnixSockets returns the unix domain sockets with their owning process, like ss -xp.
+func GetUnixSockets() ([]UnixSocket, error) {
+	sockets, err := readUnixSockets("/proc/net/unix")
+	if err != nil {
+		return nil, err
+	}
+	owners := socketOwners()
+	for i := range sockets {
+		if owner, ok := owners[sockets[i].Inode]; ok {
+			sockets[i].PID = owner.PID
+			sockets[i].Command = owner.Command
+		}
+	}
+	return sockets, nil
+}
+
+// readUnixSockets parses a file in the format of /proc/net/unix.
+func readUnixSockets(path string) ([]UnixSocket, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var sockets []UnixSocket
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // Skip the header line
+	for scanner.Scan() {
+		// "00000000f7ce27a8: 00000002 00000000 00010000 0001 01 12502 /run/app.sock"
+		// The path is optional and may contain spaces, so it is the rest of the line after seven
+		// fields and the single space the kernel writes before it.
+		var fields []string
+		rest := strings.TrimLeft(scanner.Text(), " ")
+		for len(fields) < 7 && rest != "" {
+			var field string
+			field, rest, _ = strings.Cut(rest, " ")
+			fields = append(fields, field)
+			if len(fields) < 7 {
+				rest = strings.TrimLeft(rest, " ")
+			}
+		}
+		if len(fields) < 7 {
+			continue
+		}
+		flags, _ := strconv.ParseUint(fields[3], 16, 32)
+		socketType, _ := strconv.ParseUint(fields[4], 16, 16)
+		state, _ := strconv.ParseUint(fields[5], 16, 8)
+		socket := UnixSocket{
+			Type:      unixSocketTypes[socketType],
+			State:     unixSocketStates[state],
+			Listening: flags&soAcceptCon != 0,
+		}
+		socket.Inode, _ = strconv.ParseUint(fields[6], 10, 64)
+		socket.Path = rest
+		sockets = append(sockets, socket)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	return sockets, nil
+}
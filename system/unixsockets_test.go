@@ -0,0 +1,36 @@
+package system
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadUnixSockets(t *testing.T) {
+	path := writeProcFixture(t, "unix", ""+
+		"Num       RefCount Protocol Flags    Type St Inode Path\n"+
+		"0000000000000000: 00000002 00000000 00010000 0001 01 12502 /run/systemd/notify\n"+
+		"0000000000000000: 00000002 00000000 00010000 0001 01  3210 @/tmp/.X11-unix/X0\n"+
+		"0000000000000000: 00000003 00000000 00000000 0001 03 45678\n"+
+		"0000000000000000: 00000002 00000000 00000000 0002 01 45679 /home/user/My Sockets/two  spaces.sock\n"+
+		"0000000000000000: 00000002 00000000 00000000 0005 02 45680 @\n"+
+		"0000000000000000: 00000002 00000000 00000000 0001 04 45681  leading-space\n"+
+		"truncated line\n")
+
+	sockets, err := readUnixSockets(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []UnixSocket{
+		{Path: "/run/systemd/notify", Type: "stream", State: "UNCONNECTED", Listening: true, Inode: 12502},
+		{Path: "@/tmp/.X11-unix/X0", Type: "stream", State: "UNCONNECTED", Listening: true, Inode: 3210},
+		// An unnamed socket, such as one end of a socketpair.
+		{Path: "", Type: "stream", State: "CONNECTED", Inode: 45678},
+		{Path: "/home/user/My Sockets/two  spaces.sock", Type: "dgram", State: "UNCONNECTED", Inode: 45679},
+		// An abstract name made only of null bytes.
+		{Path: "@", Type: "seqpacket", State: "CONNECTING", Inode: 45680},
+		{Path: " leading-space", Type: "stream", State: "DISCONNECTING", Inode: 45681},
+	}
+	if !reflect.DeepEqual(sockets, want) {
+		t.Errorf("sockets:\n got %+v\nwant %+v", sockets, want)
+	}
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"strconv"
@@ -45,8 +46,15 @@ func GetNetworkStats() (map[string][2]int64, error) {
 	return stats, nil
 }
 
-// GetActiveInterface returns the name of the active network interface.
+// GetActiveInterface returns the name of the active network interface: the interface of the
+// default route, or, when there is none, the interface that received the most bytes.
 func GetActiveInterface(stats map[string][2]int64) (string, error) {
+	if route, err := DefaultRoute(); err == nil {
+		if _, ok := stats[route.Interface]; ok {
+			return route.Interface, nil
+		}
+	}
+
 	maxBytes := int64(0)
 	activeInterface := ""
 	for iface, bytes := range stats {
@@ -64,9 +72,21 @@ func GetActiveInterface(stats map[string][2]int64) (string, error) {
 
 // CalculateNetworkRates calculates the download and upload rates for the active network interface.
 func CalculateNetworkRates(initialStats map[string][2]int64, interfaceName string) (downloadRate, uploadRate int64, err error) {
+	return CalculateNetworkRatesWindow(context.Background(), initialStats, interfaceName, time.Second)
+}
+
+// CalculateNetworkRatesWindow calculates the download and upload rates, in KBps, of an interface
+// over the given window. It returns early with the context error if ctx is done before the window ends.
+func CalculateNetworkRatesWindow(ctx context.Context, initialStats map[string][2]int64, interfaceName string, window time.Duration) (downloadRate, uploadRate int64, err error) {
 	initialBytes := initialStats[interfaceName]
 
-	time.Sleep(1 * time.Second)
+	timer := time.NewTimer(window)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return 0, 0, ctx.Err()
+	case <-timer.C:
+	}
 
 	finalStats, err := GetNetworkStats()
 	if err != nil {
@@ -75,8 +95,9 @@ func CalculateNetworkRates(initialStats map[string][2]int64, interfaceName strin
 
 	finalBytes := finalStats[interfaceName]
 
-	downloadRate = (finalBytes[0] - initialBytes[0]) / 1024 // Calculate in KBps
-	uploadRate = (finalBytes[1] - initialBytes[1]) / 1024   // Calculate in KBps
+	seconds := window.Seconds()
+	downloadRate = int64(float64(finalBytes[0]-initialBytes[0]) / seconds / 1024) // Calculate in KBps
+	uploadRate = int64(float64(finalBytes[1]-initialBytes[1]) / seconds / 1024)   // Calculate in KBps
 
 	return downloadRate, uploadRate, nil
 }
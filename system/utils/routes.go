@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Route flags, from include/uapi/linux/route.h.
+const (
+	RouteFlagUp      = 0x0001 // The route is usable.
+	RouteFlagGateway = 0x0002 // The destination is reached through a gateway.
+	RouteFlagHost    = 0x0004 // The destination is a single host.
+	RouteFlagReject  = 0x0200 // Packets to the destination are rejected.
+)
+
+// ErrNoDefaultRoute is returned by DefaultRoute when neither IPv4 nor IPv6 has a usable default route.
+var ErrNoDefaultRoute = errors.New("no default route")
+
+// Route is an entry of the kernel routing table, from /proc/net/route or /proc/net/ipv6_route.
+type Route struct {
+	Family       string // "ipv4" or "ipv6".
+	Destination  net.IP
+	PrefixLength int
+	Gateway      net.IP // Unspecified (0.0.0.0 or ::) for directly connected destinations.
+	Metric       uint32
+	Flags        uint32
+	Interface    string
+}
+
+// Mask returns the destination mask, e.g. 255.255.255.0 for an IPv4 /24 route.
+func (r Route) Mask() net.IPMask {
+	return net.CIDRMask(r.PrefixLength, len(r.Destination)*8)
+}
+
+// IsDefault reports whether the route matches every destination.
+func (r Route) IsDefault() bool {
+	return r.PrefixLength == 0
+}
+
+// Up reports whether the route is usable.
+func (r Route) Up() bool {
+	return r.Flags&RouteFlagUp != 0
+}
+
+// HasGateway reports whether the destination is reached through a gateway.
+func (r Route) HasGateway() bool {
+	return r.Flags&RouteFlagGateway != 0
+}
+
+// Reject reports whether the route rejects packets, like the unreachable IPv6 default route
+// the kernel adds on the loopback interface.
+func (r Route) Reject() bool {
+	return r.Flags&RouteFlagReject != 0
+}
+
+// GetRoutes returns the IPv4 and IPv6 routing tables.
+func GetRoutes() ([]Route, error) {
+	routes, err := readIPv4Routes()
+	if err != nil {
+		return nil, err
+	}
+	ipv6Routes, err := readIPv6Routes()
+	if err != nil && !os.IsNotExist(err) { // The file is missing when IPv6 is disabled.
+		return nil, err
+	}
+	return append(routes, ipv6Routes...), nil
+}
+
+// DefaultRoute returns the usable default route with the lowest metric, preferring IPv4,
+// which gives the default gateway and the egress interface.
+func DefaultRoute() (Route, error) {
+	routes, err := GetRoutes()
+	if err != nil {
+		return Route{}, err
+	}
+	var best *Route
+	for i, route := range routes {
+		if !route.IsDefault() || !route.Up() || route.Reject() {
+			continue
+		}
+		if best == nil || (route.Family == best.Family && route.Metric < best.Metric) ||
+			(route.Family == "ipv4" && best.Family == "ipv6") {
+			best = &routes[i]
+		}
+	}
+	if best == nil {
+		return Route{}, ErrNoDefaultRoute
+	}
+	return *best, nil
+}
+
+// readIPv4Routes parses /proc/net/route. Lines look like
+// "eth0 00000000 0101A8C0 0003 0 0 100 00000000 0 0 0", with the addresses in little-endian hex.
+func readIPv4Routes() ([]Route, error) {
+	file, err := os.Open("/proc/net/route")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var routes []Route
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // Skip the header line
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 {
+			continue
+		}
+		destination := ParseHexIP(fields[1])
+		gateway := ParseHexIP(fields[2])
+		mask := ParseHexIP(fields[7])
+		if destination == nil || gateway == nil || mask == nil {
+			return nil, fmt.Errorf("invalid /proc/net/route line: %q", scanner.Text())
+		}
+		flags, _ := strconv.ParseUint(fields[3], 16, 32)
+		metric, _ := strconv.ParseUint(fields[6], 10, 32)
+		prefix, _ := net.IPMask(mask).Size()
+		routes = append(routes, Route{
+			Family:       "ipv4",
+			Destination:  destination,
+			PrefixLength: prefix,
+			Gateway:      gateway,
+			Metric:       uint32(metric),
+			Flags:        uint32(flags),
+			Interface:    fields[0],
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading /proc/net/route: %v", err)
+	}
+	return routes, nil
+}
+
+// readIPv6Routes parses /proc/net/ipv6_route. Lines hold the destination, its prefix length,
+// the source and its prefix length, the next hop, the metric, the reference and use counts,
+// the flags and the interface. Unlike /proc/net/tcp6, the addresses are in network byte order.
+func readIPv6Routes() ([]Route, error) {
+	file, err := os.Open("/proc/net/ipv6_route")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var routes []Route
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		destination, err := hex.DecodeString(fields[0])
+		if err != nil || len(destination) != net.IPv6len {
+			return nil, fmt.Errorf("invalid /proc/net/ipv6_route line: %q", scanner.Text())
+		}
+		gateway, err := hex.DecodeString(fields[4])
+		if err != nil || len(gateway) != net.IPv6len {
+			return nil, fmt.Errorf("invalid /proc/net/ipv6_route line: %q", scanner.Text())
+		}
+		prefix, _ := strconv.ParseUint(fields[1], 16, 8)
+		metric, _ := strconv.ParseUint(fields[5], 16, 32)
+		flags, _ := strconv.ParseUint(fields[8], 16, 32)
+		routes = append(routes, Route{
+			Family:       "ipv6",
+			Destination:  net.IP(destination),
+			PrefixLength: int(prefix),
+			Gateway:      net.IP(gateway),
+			Metric:       uint32(metric),
+			Flags:        uint32(flags),
+			Interface:    fields[9],
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading /proc/net/ipv6_route: %v", err)
+	}
+	return routes, nil
+}
+
+// ParseHexIP parses an address as printed in /proc/net: IPv4 as one 32-bit word and IPv6 as
+// four 32-bit words, each in little-endian byte order.
+func ParseHexIP(hexIP string) net.IP {
+	if len(hexIP) != 8 && len(hexIP) != 32 {
+		return nil
+	}
+	ip := make(net.IP, len(hexIP)/2)
+	for word := 0; word < len(ip); word += 4 {
+		for i := 0; i < 4; i++ {
+			offset := (word + i) * 2
+			byteVal, err := strconv.ParseUint(hexIP[offset:offset+2], 16, 8)
+			if err != nil {
+				return nil
+			}
+			ip[word+3-i] = byte(byteVal)
+		}
+	}
+	return ip
+}
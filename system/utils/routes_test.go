@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"net"
+	"testing"
+)
+
+func TestParseHexIP(t *testing.T) {
+	tests := []struct {
+		in   string
+		want net.IP
+	}{
+		{"0101A8C0", net.IPv4(192, 168, 1, 1).To4()},
+		{"00000000000000000000000001000000", net.IPv6loopback},
+		{"0101A8C", nil},
+		{"zz01A8C0", nil},
+	}
+	for _, tt := range tests {
+		if got := ParseHexIP(tt.in); !got.Equal(tt.want) || (got == nil) != (tt.want == nil) {
+			t.Errorf("ParseHexIP(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGetActiveInterfaceUsesDefaultRoute(t *testing.T) {
+	route, err := DefaultRoute()
+	if err != nil {
+		t.Skip("no default route:", err)
+	}
+	stats := map[string][2]int64{route.Interface: {0, 0}, "busy0": {1 << 40, 0}}
+	if got, err := GetActiveInterface(stats); err != nil || got != route.Interface {
+		t.Errorf("GetActiveInterface = %q, %v; want the default route interface %q", got, err, route.Interface)
+	}
+}